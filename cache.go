@@ -1,13 +1,17 @@
 package launchbar
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +29,7 @@ var (
 
 // Cache provides tools for non permanent storage
 type Cache struct {
+	HTTP *HTTPClient // used by FetchURL, defaults to http.DefaultClient
 	path string
 }
 
@@ -47,6 +52,11 @@ func (c *Cache) Delete(key string) {
 	if !path.IsAbs(c.path) || path.Dir(c.path) != os.ExpandEnv("$HOME/Library/Caches/at.obdev.LaunchBar/Actions") {
 		panic(fmt.Sprintf("bad cache path: %q", c.path))
 	}
+	c.remove(key)
+}
+
+// remove removes the cachefile of the key if it's a file in the cache directory.
+func (c *Cache) remove(key string) {
 	p := path.Join(c.path, key)
 	if stat, err := os.Stat(p); err == nil {
 		if !stat.IsDir() {
@@ -58,18 +68,27 @@ func (c *Cache) Delete(key string) {
 }
 
 // Set stores the data in a file identified by the key and with the lifetime of d
+//
+// The file is replaced atomically so a concurrent Get never reads a partial file.
 func (c *Cache) Set(key string, data interface{}, d time.Duration) {
-	wd, err := os.Create(path.Join(c.path, key))
+	t := time.Now().Add(d)
+	b, err := json.Marshal(genericCache{&t, data})
 	if err != nil {
 		log.Fatalln(err)
 	}
-	defer wd.Close()
-	t := time.Now().Add(d)
-	b, err := json.Marshal(genericCache{&t, data})
+	p := path.Join(c.path, key)
+	// the temp file must be next to p to be renamed, TempFile("") would
+	// create it in os.TempDir
+	wd, err := ioutil.TempFile(filepath.Dir(p), "."+path.Base(p)+".")
 	if err != nil {
 		log.Fatalln(err)
 	}
 	wd.Write(b)
+	wd.Close()
+	if err := os.Rename(wd.Name(), p); err != nil {
+		os.Remove(wd.Name())
+		log.Fatalln(err)
+	}
 }
 
 // Get the data from cachefile specified by the key and stores it into the value pointed to by v
@@ -161,3 +180,98 @@ func (c *Cache) GetItems(key string) *Items {
 	items, _, _ := c.GetItemsWithInfo(key)
 	return items
 }
+
+type urlCache struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// FetchURL returns the body of the url, fetching it only if the cached copy is
+// older than ttl. The cached copy is revalidated with If-None-Match and
+// If-Modified-Since, a max-age in the Cache-Control response header takes
+// precedence over ttl and no-store disables the caching.
+//
+// If the server cannot be reached or responds with a server error, the stale
+// copy (if any) is returned with ErrCacheIsExpired.
+func (c *Cache) FetchURL(url string, ttl time.Duration) ([]byte, error) {
+	key := fmt.Sprintf("url-%x", sha1.Sum([]byte(url)))
+	var cached urlCache
+	_, err := c.Get(key, &cached)
+	if err == nil && cached.URL == url {
+		return cached.Body, nil
+	}
+	stale := (err == nil || err == ErrCacheIsExpired) && cached.URL == url
+	if !stale {
+		cached = urlCache{}
+	}
+
+	h := c.HTTP
+	if h == nil {
+		h = &HTTPClient{Client: http.DefaultClient}
+	}
+	resp, err := h.GetIfModified(url, cached.ETag, cached.LastModified)
+	if err != nil {
+		if stale {
+			return cached.Body, ErrCacheIsExpired
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && stale:
+	case resp.StatusCode >= 500 && stale:
+		return cached.Body, ErrCacheIsExpired
+	case resp.StatusCode >= 400:
+		return nil, fmt.Errorf("cannot get %s: %s", url, resp.Status)
+	default:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			if stale {
+				return cached.Body, ErrCacheIsExpired
+			}
+			return nil, err
+		}
+		cached = urlCache{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+		}
+	}
+
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		c.remove(key)
+		return cached.Body, nil
+	}
+	if _, ok := cc["no-cache"]; ok {
+		ttl = 0
+	} else if s, ok := cc["max-age"]; ok {
+		if n, err := strconv.Atoi(s); err == nil {
+			ttl = time.Duration(n) * time.Second
+		}
+	}
+	c.Set(key, cached, ttl)
+	return cached.Body, nil
+}
+
+func parseCacheControl(s string) map[string]string {
+	cc := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		k := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			cc[k] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		} else {
+			cc[k] = ""
+		}
+	}
+	return cc
+}
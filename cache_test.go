@@ -0,0 +1,124 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheFetchURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hits, full := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Path == "/maxage" {
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+		}
+		w.Write([]byte("body"))
+	}))
+
+	c := NewCache(dir)
+	for i := 0; i < 3; i++ {
+		b, err := c.FetchURL(ts.URL, 0)
+		if err != nil || string(b) != "body" {
+			t.Fatalf("FetchURL: %q %v", b, err)
+		}
+	}
+	if hits != 3 || full != 1 {
+		t.Errorf("expected 3 requests with 1 full response got %d, %d", hits, full)
+	}
+
+	hits = 0
+	for i := 0; i < 2; i++ {
+		if _, err := c.FetchURL(ts.URL+"/maxage", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("max-age should be obeyed, got %d requests", hits)
+	}
+
+	ts.Close()
+	b, err := c.FetchURL(ts.URL, time.Hour)
+	if err != ErrCacheIsExpired || string(b) != "body" {
+		t.Errorf("expected the stale copy when offline got %q %v", b, err)
+	}
+}
+
+func TestCacheFetchURLNoStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	noStore := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if noStore {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Write([]byte("body"))
+	}))
+	defer ts.Close()
+
+	// dir is not the LaunchBar cache directory, Delete would panic here
+	c := NewCache(dir)
+	files := func() int {
+		fis, _ := ioutil.ReadDir(dir)
+		return len(fis)
+	}
+	if _, err := c.FetchURL(ts.URL, 0); err != nil || files() != 1 {
+		t.Fatalf("FetchURL did not cache the response: %v", err)
+	}
+
+	noStore = true
+	b, err := c.FetchURL(ts.URL, 0)
+	if err != nil || string(b) != "body" {
+		t.Fatalf("FetchURL: %q %v", b, err)
+	}
+	if n := files(); n != 0 {
+		t.Errorf("expected the cached copy to be removed on no-store, got %d files", n)
+	}
+}
+
+func TestCacheSetEmptyPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	// the temp file must not be created in os.TempDir
+	t.Setenv("TMPDIR", filepath.Join(dir, "missing"))
+	t.Setenv("LB_CACHE_PATH", "")
+
+	c := NewCache(os.Getenv("LB_CACHE_PATH"))
+	c.Set("key", "value", time.Hour)
+	var v string
+	if _, err := c.Get("key", &v); err != nil || v != "value" {
+		t.Errorf("Get = %q, %v", v, err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "key" {
+		t.Errorf("the cache dir has %d files, want only key", len(files))
+	}
+}
@@ -0,0 +1,246 @@
+package launchbar
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// changelog represents a parsed LBChangelog, either plain Markdown or the
+// "Keep a Changelog" format (http://keepachangelog.com).
+type changelog struct {
+	Sections []*changelogSection
+	Links    map[string]string // link reference definitions, e.g. [1.0.0]: https://...
+	Raw      string
+}
+
+// changelogSection is a version section of the changelog, e.g. "## [1.2.0] - 2015-06-20".
+type changelogSection struct {
+	Version Version
+	Date    string
+	Entries []*changelogEntry
+}
+
+// changelogEntry is a single bullet of a section. Group is the sub heading
+// the bullet appears under (Added, Changed, Fixed, ...).
+type changelogEntry struct {
+	Text  string
+	Group string
+	Links []changelogLink
+}
+
+type changelogLink struct {
+	Text string
+	URL  string
+}
+
+var (
+	reChangelogVersion = regexp.MustCompile(`^#{1,3}\s*\[?v?(\d+(?:\.\d+)*)\]?(?:\s*[-–—]\s*(.*))?$`)
+	reChangelogHeading = regexp.MustCompile(`^#{1,6}\s*(.*?)\s*#*$`)
+	reChangelogBullet  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	reChangelogLinkDef = regexp.MustCompile(`^\s*\[([^\]]+)\]:\s*(\S+)`)
+	reChangelogLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	reChangelogURL     = regexp.MustCompile(`<?(https?://[^\s>)]+)>?`)
+)
+
+// parseChangelog parses s. Lines that are not under a version heading are
+// ignored, if there are no version headings at all the returned changelog has
+// no sections.
+func parseChangelog(s string) *changelog {
+	cl := &changelog{Links: make(map[string]string), Raw: s}
+	var section *changelogSection
+	var group string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := reChangelogLinkDef.FindStringSubmatch(line); m != nil {
+			cl.Links[m[1]] = m[2]
+			continue
+		}
+		if m := reChangelogVersion.FindStringSubmatch(line); m != nil {
+			section = &changelogSection{Version: Version(m[1]), Date: strings.TrimSpace(m[2])}
+			cl.Sections = append(cl.Sections, section)
+			group = ""
+			continue
+		}
+		if m := reChangelogHeading.FindStringSubmatch(line); m != nil {
+			if strings.HasPrefix(line, "## ") || strings.HasPrefix(line, "# ") {
+				// a non version top level section (e.g. Unreleased)
+				section = nil
+			}
+			group = m[1]
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		text := strings.TrimSpace(line)
+		if m := reChangelogBullet.FindStringSubmatch(line); m != nil {
+			text = m[1]
+		} else if n := len(section.Entries); n > 0 && strings.HasPrefix(line, " ") {
+			// continuation of the previous bullet
+			e := section.Entries[n-1]
+			e.Text += " " + text
+			e.Links = changelogLinks(e.Text)
+			continue
+		}
+		section.Entries = append(section.Entries, &changelogEntry{
+			Text:  text,
+			Group: group,
+			Links: changelogLinks(text),
+		})
+	}
+	return cl
+}
+
+// changelogLinks returns the markdown links and bare URLs found in s.
+func changelogLinks(s string) []changelogLink {
+	var links []changelogLink
+	for _, m := range reChangelogLink.FindAllStringSubmatch(s, -1) {
+		links = append(links, changelogLink{m[1], m[2]})
+	}
+	s = reChangelogLink.ReplaceAllString(s, "")
+	for _, m := range reChangelogURL.FindAllStringSubmatch(s, -1) {
+		links = append(links, changelogLink{m[1], m[1]})
+	}
+	return links
+}
+
+// changelogText strips the markdown links and emphasis from s.
+func changelogText(s string) string {
+	s = reChangelogLink.ReplaceAllString(s, "$1")
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(s)
+}
+
+// Newer returns the sections with a version greater than v.
+func (cl *changelog) Newer(v Version) []*changelogSection {
+	var out []*changelogSection
+	for _, s := range cl.Sections {
+		if v.Less(s.Version) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Items returns one item per section newer than v with its bullets as the
+// children. If the changelog has no version sections it falls back to one item
+// per line. qlurl is set as the QuickLookURL of each item if it's not empty.
+func (cl *changelog) Items(v Version, qlurl string) *Items {
+	items := NewItems()
+	if len(cl.Sections) == 0 {
+		for _, line := range strings.Split(cl.Raw, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			items.Add(NewItem(line).SetIcon("at.obdev.LaunchBar:ContentsTemplate").SetQuickLookURL(qlurl))
+		}
+		return items
+	}
+
+	for _, s := range cl.Newer(v) {
+		i := NewItem(fmt.Sprintf("v%s", s.Version)).
+			SetSubtitle(s.Date).
+			SetIcon("at.obdev.LaunchBar:ContentsTemplate").
+			SetQuickLookURL(qlurl)
+		if u, ok := cl.Links[string(s.Version)]; ok {
+			i.SetURL(u)
+		}
+		children := NewItems()
+		for _, e := range s.Entries {
+			child := NewItem(changelogText(e.Text)).SetSubtitle(e.Group).SetQuickLookURL(qlurl)
+			switch len(e.Links) {
+			case 0:
+				child.SetIcon("at.obdev.LaunchBar:ContentsTemplate")
+			case 1:
+				child.SetURL(e.Links[0].URL)
+			default:
+				links := NewItems()
+				for _, l := range e.Links {
+					links.Add(NewItem(l.Text).SetURL(l.URL))
+				}
+				child.SetChildren(links)
+			}
+			children.Add(child)
+		}
+		if len(*children) > 0 {
+			i.SetChildren(children)
+		}
+		items.Add(i)
+	}
+	return items
+}
+
+// HTML renders the whole changelog as a standalone html document suitable for
+// QuickLook.
+func (cl *changelog) HTML(title string) string {
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">")
+	fmt.Fprintf(&b, "<title>%s</title>", html.EscapeString(title))
+	b.WriteString(`<style>body{font:13px -apple-system,Helvetica,sans-serif;margin:2em;}h2{border-bottom:1px solid #ddd;}</style>`)
+	b.WriteString("</head><body>\n")
+	inList := false
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+	for _, line := range strings.Split(cl.Raw, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			closeList()
+		case reChangelogLinkDef.MatchString(line):
+		case strings.HasPrefix(line, "#"):
+			closeList()
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if level > 6 {
+				level = 6
+			}
+			m := reChangelogHeading.FindStringSubmatch(line)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, changelogHTML(m[1], cl.Links), level)
+		case reChangelogBullet.MatchString(line):
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			m := reChangelogBullet.FindStringSubmatch(line)
+			fmt.Fprintf(&b, "<li>%s</li>\n", changelogHTML(m[1], cl.Links))
+		default:
+			closeList()
+			fmt.Fprintf(&b, "<p>%s</p>\n", changelogHTML(strings.TrimSpace(line), cl.Links))
+		}
+	}
+	closeList()
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+// changelogHTML escapes s and converts its markdown links to anchors.
+func changelogHTML(s string, refs map[string]string) string {
+	var b bytes.Buffer
+	last := 0
+	for _, m := range reChangelogLink.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(html.EscapeString(s[last:m[0]]))
+		fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(s[m[4]:m[5]]), html.EscapeString(s[m[2]:m[3]]))
+		last = m[1]
+	}
+	rest := s[last:]
+	// reference style links, e.g. ## [1.0.0]
+	if strings.HasPrefix(rest, "[") {
+		if end := strings.Index(rest, "]"); end > 0 {
+			if u, ok := refs[rest[1:end]]; ok {
+				fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(u), html.EscapeString(rest[1:end]))
+				rest = rest[end+1:]
+			}
+		}
+	}
+	b.WriteString(html.EscapeString(rest))
+	return b.String()
+}
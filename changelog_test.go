@@ -0,0 +1,70 @@
+package launchbar
+
+import "testing"
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+- not released yet
+
+## [1.2.0] - 2015-06-20
+### Added
+- Search by [tag](https://example.com/tags)
+- Faster startup
+  on large libraries
+
+## 1.1.0
+### Fixed
+- Crash on empty input, see https://example.com/issues/1
+
+## [1.0.0] - 2015-01-01
+- Initial release
+
+[1.2.0]: https://example.com/compare/v1.1.0...v1.2.0
+`
+
+func TestParseChangelog(t *testing.T) {
+	cl := parseChangelog(testChangelog)
+	if len(cl.Sections) != 3 {
+		t.Fatalf("expected 3 sections got %d", len(cl.Sections))
+	}
+	s := cl.Sections[0]
+	if s.Version != "1.2.0" || s.Date != "2015-06-20" {
+		t.Errorf("bad section header: %q %q", s.Version, s.Date)
+	}
+	if len(s.Entries) != 2 {
+		t.Fatalf("expected 2 entries got %d", len(s.Entries))
+	}
+	if s.Entries[0].Group != "Added" || len(s.Entries[0].Links) != 1 || s.Entries[0].Links[0].URL != "https://example.com/tags" {
+		t.Errorf("bad entry: %#v", s.Entries[0])
+	}
+	if s.Entries[1].Text != "Faster startup on large libraries" {
+		t.Errorf("continuation line not joined: %q", s.Entries[1].Text)
+	}
+	if l := cl.Sections[1].Entries[0].Links; len(l) != 1 || l[0].URL != "https://example.com/issues/1" {
+		t.Errorf("bare url not detected: %#v", l)
+	}
+	if cl.Links["1.2.0"] == "" {
+		t.Errorf("link reference not parsed")
+	}
+
+	newer := cl.Newer("1.0.0")
+	if len(newer) != 2 {
+		t.Errorf("expected 2 newer sections got %d", len(newer))
+	}
+
+	items := *cl.Items("1.1.0", "")
+	if len(items) != 1 || items[0].item.Title != "v1.2.0" || items[0].item.URL == "" || len(items[0].item.Children) != 2 {
+		t.Errorf("bad items: %#v", items)
+	}
+}
+
+func TestParseChangelogPlain(t *testing.T) {
+	cl := parseChangelog("fixed a bug\n\nadded a feature\n")
+	if len(cl.Sections) != 0 {
+		t.Fatalf("expected no sections got %d", len(cl.Sections))
+	}
+	if items := *cl.Items("1.0", ""); len(items) != 2 {
+		t.Errorf("expected 2 items got %d", len(items))
+	}
+}
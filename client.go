@@ -0,0 +1,223 @@
+package launchbar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// LaunchBarClient performs the AppleScript operations of LaunchBar that an
+// action needs. The default client runs osascript, use a RecordingClient in
+// tests.
+type LaunchBarClient interface {
+	// PerformAction runs the action by name with arg as the input, an empty
+	// arg runs it without input.
+	PerformAction(name, arg string) error
+	// DisplayText shows text in large type.
+	DisplayText(text string) error
+	// ShowNotification shows a notification with the title and text.
+	ShowNotification(title, text string) error
+	// Paste pastes text into the frontmost application.
+	Paste(text string) error
+	// SetClipboard copies text to the clipboard.
+	SetClipboard(text string) error
+	// OpenURL opens the url.
+	OpenURL(url string) error
+}
+
+// OsascriptClient is a LaunchBarClient that tells LaunchBar what to do with
+// osascript. It waits for osascript, a failed script is returned as an error
+// with the output of osascript, except in PerformAction.
+type OsascriptClient struct{}
+
+// start runs the script without waiting for it, only the errors to start
+// osascript are returned.
+func (OsascriptClient) start(script string) error {
+	cmd := exec.Command("osascript", "-e", script)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("osascript: %v", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func (OsascriptClient) run(script string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("osascript", "-e", script)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("osascript: %v: %s", err, msg)
+		}
+		return fmt.Errorf("osascript: %v", err)
+	}
+	return nil
+}
+
+// PerformAction runs the action. It doesn't wait for osascript: LaunchBar
+// runs the action after this one exits, e.g. when it's the same action.
+func (c OsascriptClient) PerformAction(name, arg string) error {
+	return c.start(performActionScript(name, arg))
+}
+
+// DisplayText shows text in large type.
+func (c OsascriptClient) DisplayText(text string) error {
+	return c.run(tellLaunchBar(fmt.Sprintf(`display in large type %s`, appleScriptString(text))))
+}
+
+// ShowNotification shows a notification in the notification center.
+func (c OsascriptClient) ShowNotification(title, text string) error {
+	return c.run(tellLaunchBar(fmt.Sprintf(`display in notification center %s with title %s`, appleScriptString(text), appleScriptString(title))))
+}
+
+// Paste pastes text into the frontmost application.
+func (c OsascriptClient) Paste(text string) error {
+	return c.run(tellLaunchBar(fmt.Sprintf(`paste in frontmost application %s`, appleScriptString(text))))
+}
+
+// SetClipboard copies text to the clipboard.
+func (c OsascriptClient) SetClipboard(text string) error {
+	return c.run(fmt.Sprintf(`set the clipboard to %s`, appleScriptString(text)))
+}
+
+// OpenURL opens the url.
+func (c OsascriptClient) OpenURL(url string) error {
+	return c.run(fmt.Sprintf(`open location %s`, appleScriptString(url)))
+}
+
+func tellLaunchBar(cmd string) string {
+	return fmt.Sprintf("tell application \"LaunchBar\"\n\t%s\nend tell", cmd)
+}
+
+func performActionScript(name, arg string) string {
+	cmd := fmt.Sprintf("remain active\n\tperform action %s", appleScriptString(name))
+	if arg != "" {
+		cmd += " with string " + appleScriptString(arg)
+	}
+	return tellLaunchBar(cmd)
+}
+
+// appleScriptString returns s as a quoted AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// SimulatorEnv is the environment variable set by cmd/lbsim to the file the
+// calls of the action are written to, see FileClient.
+const SimulatorEnv = "LB_SIMULATOR_CALLS"
+
+// DefaultClient returns a FileClient if the action runs in cmd/lbsim, the
+// OsascriptClient otherwise.
+func DefaultClient() LaunchBarClient {
+	if p := os.Getenv(SimulatorEnv); p != "" {
+		return &FileClient{Path: p}
+	}
+	return OsascriptClient{}
+}
+
+// ClientCall is a call recorded by RecordingClient or FileClient.
+type ClientCall struct {
+	Method string   `json:"method"`
+	Args   []string `json:"args"`
+}
+
+// RecordingClient is a LaunchBarClient that records the calls instead of
+// running them. Err is returned by every call.
+type RecordingClient struct {
+	Err error
+
+	mu    sync.Mutex
+	calls []ClientCall
+}
+
+func (c *RecordingClient) record(method string, args ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, ClientCall{method, args})
+	return c.Err
+}
+
+// Calls returns the recorded calls.
+func (c *RecordingClient) Calls() []ClientCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ClientCall(nil), c.calls...)
+}
+
+// Reset forgets the recorded calls.
+func (c *RecordingClient) Reset() { c.mu.Lock(); c.calls = nil; c.mu.Unlock() }
+
+// PerformAction records the call.
+func (c *RecordingClient) PerformAction(name, arg string) error {
+	return c.record("PerformAction", name, arg)
+}
+
+// DisplayText records the call.
+func (c *RecordingClient) DisplayText(text string) error { return c.record("DisplayText", text) }
+
+// ShowNotification records the call.
+func (c *RecordingClient) ShowNotification(title, text string) error {
+	return c.record("ShowNotification", title, text)
+}
+
+// Paste records the call.
+func (c *RecordingClient) Paste(text string) error { return c.record("Paste", text) }
+
+// SetClipboard records the call.
+func (c *RecordingClient) SetClipboard(text string) error { return c.record("SetClipboard", text) }
+
+// OpenURL records the call.
+func (c *RecordingClient) OpenURL(url string) error { return c.record("OpenURL", url) }
+
+// FileClient is a LaunchBarClient that appends the calls to the file at Path,
+// a json ClientCall per line. It's used to run the action outside of
+// LaunchBar, see cmd/lbsim.
+type FileClient struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (c *FileClient) record(method string, args ...string) error {
+	b, err := json.Marshal(ClientCall{method, args})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fd, err := os.OpenFile(c.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(append(b, '\n')); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// PerformAction records the call.
+func (c *FileClient) PerformAction(name, arg string) error {
+	return c.record("PerformAction", name, arg)
+}
+
+// DisplayText records the call.
+func (c *FileClient) DisplayText(text string) error { return c.record("DisplayText", text) }
+
+// ShowNotification records the call.
+func (c *FileClient) ShowNotification(title, text string) error {
+	return c.record("ShowNotification", title, text)
+}
+
+// Paste records the call.
+func (c *FileClient) Paste(text string) error { return c.record("Paste", text) }
+
+// SetClipboard records the call.
+func (c *FileClient) SetClipboard(text string) error { return c.record("SetClipboard", text) }
+
+// OpenURL records the call.
+func (c *FileClient) OpenURL(url string) error { return c.record("OpenURL", url) }
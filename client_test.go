@@ -0,0 +1,91 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPerformActionScript(t *testing.T) {
+	tests := []struct {
+		name, arg, out string
+	}{
+		{"Test", "", "tell application \"LaunchBar\"\n\tremain active\n\tperform action \"Test\"\nend tell"},
+		{`Say "Hi"`, `a\b "c"`, "tell application \"LaunchBar\"\n\tremain active\n\tperform action \"Say \\\"Hi\\\"\" with string \"a\\\\b \\\"c\\\"\"\nend tell"},
+	}
+	for _, test := range tests {
+		if out := performActionScript(test.name, test.arg); out != test.out {
+			t.Errorf("performActionScript(%q, %q) =\n%s\nwant:\n%s", test.name, test.arg, out, test.out)
+		}
+	}
+}
+
+func TestFileClient(t *testing.T) {
+	p := path.Join(t.TempDir(), "calls")
+	t.Setenv(SimulatorEnv, p)
+	c, ok := DefaultClient().(*FileClient)
+	if !ok {
+		t.Fatalf("DefaultClient() is not a FileClient")
+	}
+	c.PerformAction("Test", "")
+	c.ShowNotification("Copied!", "a\nb")
+
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"method":"PerformAction","args":["Test",""]}
+{"method":"ShowNotification","args":["Copied!","a\nb"]}
+`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestOsascriptClientErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// a fake osascript that fails with the script on stderr
+	ioutil.WriteFile(path.Join(dir, "osascript"), []byte("#!/bin/sh\necho \"execution error: $2\" >&2\nexit 1\n"), 0755)
+	t.Setenv("PATH", dir)
+
+	err = OsascriptClient{}.OpenURL("https://example.com")
+	if err == nil || !strings.Contains(err.Error(), `execution error: open location "https://example.com"`) {
+		t.Errorf("expected the osascript error got %v", err)
+	}
+
+	a := newTestAction(t, nil)
+	a.Client = OsascriptClient{}
+	if items := a.context.Notify("Copied!", "hello"); items == nil {
+		t.Errorf("Notify did not fall back to an item")
+	}
+}
+
+func TestOsascriptClientPerformAction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("PATH", dir)
+
+	if err := (OsascriptClient{}).PerformAction("Test", ""); err == nil {
+		t.Errorf("expected an error without osascript")
+	}
+
+	// a fake osascript that waits like LaunchBar waits for the running action
+	ioutil.WriteFile(path.Join(dir, "osascript"), []byte("#!/bin/sh\nsleep 1\nexit 1\n"), 0755)
+	start := time.Now()
+	if err := (OsascriptClient{}).PerformAction("Test", ""); err != nil {
+		t.Errorf("PerformAction = %v", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("PerformAction waited %v for osascript", d)
+	}
+}
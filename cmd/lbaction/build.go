@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// runBuild cross-compiles the package for macOS into Contents/Scripts of the
+// bundle, one binary per architecture that the action script picks from.
+func runBuild(args []string, stdout io.Writer) error {
+	fs := newFlagSet("build")
+	bundle := fs.String("bundle", "", "the action bundle (default the .lbaction in the current directory)")
+	arch := fs.String("arch", "amd64,arm64", "the comma separated architectures")
+	ldflags := fs.String("ldflags", "-s -w", "the go build -ldflags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pkg := "."
+	if fs.NArg() > 0 {
+		pkg = fs.Arg(0)
+	}
+	b, err := findBundle(*bundle)
+	if err != nil {
+		return err
+	}
+
+	issues, err := launchbar.LintBundle(b)
+	if err != nil {
+		return err
+	}
+	for _, i := range issues {
+		fmt.Fprintln(os.Stderr, i)
+	}
+	if n := launchbar.LintErrors(issues); n > 0 {
+		return fmt.Errorf("%s: %d errors, see lbaction lint", b, n)
+	}
+
+	for _, a := range strings.Split(*arch, ",") {
+		cmd := goBuildCmd(b, strings.TrimSpace(a), *ldflags, pkg)
+		cmd.Stdout, cmd.Stderr = stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %v", strings.Join(cmd.Args, " "), err)
+		}
+		fmt.Fprintln(stdout, "built", cmd.Args[len(cmd.Args)-2])
+	}
+	return nil
+}
+
+// goBuildCmd returns the go build command of pkg for darwin/arch.
+func goBuildCmd(bundle, arch, ldflags, pkg string) *exec.Cmd {
+	out := filepath.Join(bundle, "Contents", "Scripts", binaryName+"-"+arch)
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", out, pkg)
+	cmd.Env = append(os.Environ(), "GOOS=darwin", "GOARCH="+arch, "CGO_ENABLED=0")
+	return cmd
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/DHowett/go-plist"
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// runBump sets CFBundleVersion of the bundle to the next major, minor or patch
+// version, or to the given version which must be greater than the current one.
+func runBump(args []string, stdout io.Writer) error {
+	fs := newFlagSet("bump")
+	bundle := fs.String("bundle", "", "the action bundle (default the .lbaction in the current directory)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	part := "patch"
+	if fs.NArg() > 0 {
+		part = fs.Arg(0)
+	}
+	b, err := findBundle(*bundle)
+	if err != nil {
+		return err
+	}
+
+	p := infoPlistPath(b)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	info, err := launchbar.ParseInfoPlist(data)
+	if err != nil {
+		return err
+	}
+	old := launchbar.Version(info.CFBundleVersion)
+	next, err := nextVersion(old, part)
+	if err != nil {
+		return err
+	}
+
+	data, err = setVersion(data, next)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: %s -> %s\n", p, old, next)
+	return nil
+}
+
+// nextVersion returns the version after v, part is major, minor, patch or the
+// new version.
+func nextVersion(v launchbar.Version, part string) (launchbar.Version, error) {
+	switch part {
+	case "major", "minor", "patch":
+		return v.Bump(part)
+	}
+	next := launchbar.Version(part)
+	if !next.Valid() {
+		return "", fmt.Errorf("%q is not major, minor, patch or a version", part)
+	}
+	if !v.Less(next) {
+		return "", fmt.Errorf("%s is not greater than the current version %s", next, v)
+	}
+	return next, nil
+}
+
+// setVersion sets CFBundleVersion in the Info.plist data. The xml format is
+// edited in place to keep the formatting, the other formats are written again.
+func setVersion(data []byte, v launchbar.Version) ([]byte, error) {
+	var m map[string]interface{}
+	format, err := plist.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	if format == plist.XMLFormat {
+		if start, end, ok := xmlVersionRange(data); ok {
+			out := append([]byte{}, data[:start]...)
+			out = append(out, v...)
+			return append(out, data[end:]...), nil
+		}
+	}
+	m["CFBundleVersion"] = string(v)
+	return plist.MarshalIndent(m, format, "\t")
+}
+
+// xmlVersionRange returns the offsets of the value of the top-level
+// CFBundleVersion in the xml plist data. The keys of the nested dicts, e.g. of
+// LBScripts, are skipped.
+func xmlVersionRange(data []byte) (int, int, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0 // of the dicts, the root dict is 1
+	key := ""  // the last top-level key
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, 0, false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "key" && depth == 1:
+				if err := dec.DecodeElement(&key, &t); err != nil {
+					return 0, 0, false
+				}
+				continue
+			case t.Name.Local == "string" && depth == 1 && key == "CFBundleVersion":
+				start := int(dec.InputOffset())
+				if bytes.HasSuffix(data[:start], []byte("/>")) {
+					return 0, 0, false
+				}
+				for {
+					end := int(dec.InputOffset())
+					tok, err := dec.Token()
+					if err != nil {
+						return 0, 0, false
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						return start, end, true
+					}
+				}
+			case t.Name.Local == "dict":
+				depth++
+			}
+			key = ""
+		case xml.EndElement:
+			if t.Name.Local == "dict" {
+				depth--
+			}
+		}
+	}
+}
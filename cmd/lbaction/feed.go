@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/DHowett/go-plist"
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// updateFeed is the plist served at LBDescription.LBUpdate, it's read by the
+// update func of go-launchbar.
+type updateFeed struct {
+	CFBundleIdentifier string            `plist:"CFBundleIdentifier"`
+	CFBundleName       string            `plist:"CFBundleName"`
+	CFBundleVersion    string            `plist:"CFBundleVersion"`
+	LBDescription      updateDescription `plist:"LBDescription"`
+}
+
+type updateDescription struct {
+	LBDownload  string `plist:"LBDownload"`
+	LBChangelog string `plist:"LBChangelog,omitempty"`
+}
+
+// runFeed writes the update feed of the bundle.
+func runFeed(args []string, stdout io.Writer) error {
+	fs := newFlagSet("feed")
+	bundle := fs.String("bundle", "", "the action bundle (default the .lbaction in the current directory)")
+	download := fs.String("download", "", "the download url of the release (default LBDescription.LBDownload)")
+	changelog := fs.String("changelog", "", "the changelog file (default LBDescription.LBChangelog)")
+	out := fs.String("o", "", "the output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	b, err := findBundle(*bundle)
+	if err != nil {
+		return err
+	}
+	info, err := launchbar.ReadInfoPlist(infoPlistPath(b))
+	if err != nil {
+		return err
+	}
+	if err := info.Validate(); err != nil {
+		return err
+	}
+
+	feed, err := newUpdateFeed(info, *download, *changelog)
+	if err != nil {
+		return err
+	}
+	data, err := plist.MarshalIndent(feed, plist.XMLFormat, "\t")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	if err := ioutil.WriteFile(*out, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %s for v%s\n", *out, feed.CFBundleVersion)
+	return nil
+}
+
+// newUpdateFeed returns the update feed of the action, download and
+// changelog override the values of the Info.plist.
+func newUpdateFeed(info *launchbar.InfoPlist, download, changelog string) (*updateFeed, error) {
+	feed := &updateFeed{
+		CFBundleIdentifier: info.CFBundleIdentifier,
+		CFBundleName:       info.CFBundleName,
+		CFBundleVersion:    info.CFBundleVersion,
+		LBDescription: updateDescription{
+			LBDownload:  info.LBDescription.LBDownload,
+			LBChangelog: info.LBDescription.LBChangelog,
+		},
+	}
+	if download != "" {
+		feed.LBDescription.LBDownload = download
+	}
+	if u, err := url.Parse(feed.LBDescription.LBDownload); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("the download url %q is not valid, use -download", feed.LBDescription.LBDownload)
+	}
+	if changelog != "" {
+		data, err := ioutil.ReadFile(changelog)
+		if err != nil {
+			return nil, err
+		}
+		feed.LBDescription.LBChangelog = string(data)
+	}
+	return feed, nil
+}
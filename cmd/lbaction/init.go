@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// actionScript is the script of the bundle that runs the binary built for the
+// architecture of the Mac, see runBuild.
+const actionScript = "default.sh"
+
+// binaryName is the name of the binaries in Contents/Scripts, suffixed with
+// the architecture.
+const binaryName = "action"
+
+var infoPlistTemplate = template.Must(template.New("Info.plist").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>{{xml .ID}}</string>
+	<key>CFBundleName</key>
+	<string>{{xml .Name}}</string>
+	<key>CFBundleVersion</key>
+	<string>1.0</string>
+	<key>LBDescription</key>
+	<dict>
+		<key>LBAuthor</key>
+		<string>{{xml .Author}}</string>
+		<key>LBSummary</key>
+		<string>{{xml .Name}}</string>
+	</dict>
+	<key>LBScripts</key>
+	<dict>
+		<key>LBDefaultScript</key>
+		<dict>
+			<key>LBScriptName</key>
+			<string>{{xml .Script}}</string>
+			<key>LBReturnsResult</key>
+			<true/>
+			<key>LBLiveFeedbackEnabled</key>
+			<true/>
+			<key>LBBackgroundKillEnabled</key>
+			<true/>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`))
+
+var scriptTemplate = template.Must(template.New("script").Parse(`#!/bin/sh
+# Runs the action binary built for the architecture of this Mac.
+dir=$(dirname "$0")
+case $(uname -m) in
+arm64) exec "$dir/{{.Binary}}-arm64" "$@" ;;
+*) exec "$dir/{{.Binary}}-amd64" "$@" ;;
+esac
+`))
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import (
+	"fmt"
+
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+func main() {
+	a := launchbar.NewAction({{printf "%q" .Name}}, launchbar.ConfigValues{
+		"actionDefaultScript": {{printf "%q" .Script}},
+	})
+	a.Init(launchbar.FuncMap{})
+
+	a.NewView("main").
+		NewItem("Hello").
+		SetSubtitle("from " + {{printf "%q" .Name}}).
+		SetRender(func(c *launchbar.Context) {
+			if !c.Input.IsEmpty() {
+				c.Self.SetTitle("Hello " + c.Input.String())
+			}
+		})
+
+	fmt.Print(a.Run())
+}
+`))
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+type initData struct {
+	Name, ID, Author, Script, Binary string
+}
+
+var reNonID = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// runInit creates Name.lbaction and main.go in the directory.
+func runInit(args []string, stdout io.Writer) error {
+	fs := newFlagSet("init")
+	id := fs.String("id", "", "the bundle identifier (default com.example.<name>)")
+	author := fs.String("author", os.Getenv("USER"), "the author of the action")
+	dir := fs.String("dir", ".", "the directory of the action")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("the name of the action is missing")
+	}
+
+	d := initData{Name: fs.Arg(0), ID: *id, Author: *author, Script: actionScript, Binary: binaryName}
+	if d.ID == "" {
+		d.ID = "com.example." + strings.Trim(strings.ToLower(reNonID.ReplaceAllString(d.Name, "-")), "-")
+	}
+	bundle := filepath.Join(*dir, d.Name+".lbaction")
+	files := []struct {
+		path string
+		tmpl *template.Template
+		mode os.FileMode
+	}{
+		{infoPlistPath(bundle), infoPlistTemplate, 0644},
+		{filepath.Join(bundle, "Contents", "Scripts", actionScript), scriptTemplate, 0755},
+		{filepath.Join(*dir, "main.go"), mainTemplate, 0644},
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f.path); err == nil {
+			return fmt.Errorf("%s already exists", f.path)
+		}
+	}
+	for _, f := range files {
+		var b bytes.Buffer
+		if err := f.tmpl.Execute(&b, d); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(f.path, b.Bytes(), f.mode); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "created", f.path)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DHowett/go-plist"
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// initAction runs init in a temp dir and returns the dir and the bundle.
+func initAction(t *testing.T, name string) (string, string) {
+	dir, err := ioutil.TempDir("", "lbaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	var out bytes.Buffer
+	if err := runInit([]string{"-dir", dir, "-author", "Sam & Co", name}, &out); err != nil {
+		t.Fatal(err)
+	}
+	return dir, filepath.Join(dir, name+".lbaction")
+}
+
+func TestInit(t *testing.T) {
+	dir, bundle := initAction(t, "My Action")
+
+	info, err := launchbar.ReadInfoPlist(infoPlistPath(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.Validate(); err != nil {
+		t.Error(err)
+	}
+	if info.CFBundleIdentifier != "com.example.my-action" || info.LBDescription.LBAuthor != "Sam & Co" {
+		t.Errorf("bad Info.plist: %+v", info)
+	}
+
+	fi, err := os.Stat(filepath.Join(bundle, "Contents", "Scripts", actionScript))
+	if err != nil || fi.Mode()&0100 == 0 {
+		t.Errorf("the action script is not executable: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "main.go"), nil, 0); err != nil {
+		t.Errorf("main.go: %v", err)
+	}
+
+	if err := runInit([]string{"-dir", dir, "My Action"}, ioutil.Discard); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("init overwrote the action: %v", err)
+	}
+}
+
+func TestBump(t *testing.T) {
+	_, bundle := initAction(t, "Test")
+	version := func() string {
+		info, err := launchbar.ReadInfoPlist(infoPlistPath(bundle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.CFBundleVersion
+	}
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "1.0.1"},
+		{[]string{"minor"}, "1.1"},
+		{[]string{"major"}, "2.0"},
+		{[]string{"2.5.1"}, "2.5.1"},
+	} {
+		if err := runBump(append([]string{"-bundle", bundle}, test.args...), ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+		if v := version(); v != test.want {
+			t.Errorf("bump %v: got %s want %s", test.args, v, test.want)
+		}
+	}
+	data, _ := ioutil.ReadFile(infoPlistPath(bundle))
+	if !bytes.Contains(data, []byte("\t<key>LBScripts</key>\n")) {
+		t.Errorf("bump did not keep the formatting:\n%s", data)
+	}
+
+	for _, arg := range []string{"2.0", "next"} {
+		if err := runBump([]string{"-bundle", bundle, arg}, ioutil.Discard); err == nil {
+			t.Errorf("bump %s did not fail", arg)
+		}
+	}
+
+	// binary plist
+	var m map[string]interface{}
+	plist.Unmarshal(data, &m)
+	bin, err := plist.Marshal(m, plist.BinaryFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err = setVersion(bin, "3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plist.Unmarshal(bin, &m); err != nil || m["CFBundleVersion"] != "3.0" || m["CFBundleName"] != "Test" {
+		t.Errorf("binary plist: %v, %v", m, err)
+	}
+}
+
+func TestSetVersionNested(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>LBScripts</key>
+	<dict>
+		<key>LBDefaultScript</key>
+		<dict>
+			<key>CFBundleVersion</key>
+			<string>0.1</string>
+		</dict>
+	</dict>
+	<key>CFBundleVersion</key>
+	<string>1.0</string>
+	<key>Versions</key>
+	<array>
+		<string>CFBundleVersion</string>
+		<string>0.2</string>
+	</array>
+</dict>
+</plist>
+`)
+	out, err := setVersion(data, "1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Replace(data, []byte("<string>1.0</string>"), []byte("<string>1.1</string>"), 1)
+	if !bytes.Equal(out, want) {
+		t.Errorf("setVersion changed more than the top-level version:\n%s", out)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	dir, bundle := initAction(t, "Test")
+	changelog := filepath.Join(dir, "CHANGELOG.md")
+	ioutil.WriteFile(changelog, []byte("## 1.0\n- first\n"), 0644)
+
+	var out bytes.Buffer
+	if err := runFeed([]string{"-bundle", bundle}, &out); err == nil {
+		t.Errorf("feed without a download url did not fail")
+	}
+	out.Reset()
+	if err := runFeed([]string{"-bundle", bundle, "-download", "https://example.com/Test.lbaction.zip", "-changelog", changelog}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := launchbar.ParseInfoPlist(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CFBundleVersion != "1.0" || info.LBDescription.LBDownload != "https://example.com/Test.lbaction.zip" || info.LBDescription.LBChangelog != "## 1.0\n- first\n" {
+		t.Errorf("bad feed: %+v", info)
+	}
+}
+
+func TestGoBuildCmd(t *testing.T) {
+	cmd := goBuildCmd("Test.lbaction", "arm64", "-s -w", "./cmd/test")
+	want := []string{"go", "build", "-trimpath", "-ldflags", "-s -w", "-o", filepath.Join("Test.lbaction", "Contents", "Scripts", "action-arm64"), "./cmd/test"}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q", cmd.Args)
+	}
+	env := strings.Join(cmd.Env, "\n")
+	for _, v := range []string{"GOOS=darwin", "GOARCH=arm64", "CGO_ENABLED=0"} {
+		if !strings.Contains(env, "\n"+v) {
+			t.Errorf("%s is not set", v)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir, bundle := initAction(t, "Test")
+	var out bytes.Buffer
+	if err := runLint([]string{"-bundle", bundle}, &out); err != nil || out.Len() > 0 {
+		t.Errorf("lint of a new action: %v\n%s", err, out.String())
+	}
+
+	feed := filepath.Join(dir, "update.plist")
+	ioutil.WriteFile(feed, []byte(`<plist><dict><key>CFBundleVersion</key><string>1.0</string></dict></plist>`), 0644)
+	out.Reset()
+	err := runLint([]string{"-bundle", bundle, "-script", "items.sh", "-feed", feed}, &out)
+	if err == nil || !strings.Contains(err.Error(), "2 errors, 0 warnings") {
+		t.Errorf("lint err = %v", err)
+	}
+	for _, s := range []string{"items.sh does not exist", "LBUpdate: LBDescription.LBDownload: error: is missing"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("%q is not reported:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestReadFeedTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	defer func(c launchbar.HTTPClient) { *feedClient = c }(*feedClient)
+	feedClient.Client = &http.Client{Timeout: 50 * time.Millisecond}
+	feedClient.Retries = 0
+	start := time.Now()
+	if _, err := readFeed(ts.URL); err == nil {
+		t.Errorf("readFeed of a hanging server did not fail")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("readFeed took %v", d)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// runLint reports the issues of the bundle and of its update feed, it fails
+// if there is an error.
+func runLint(args []string, stdout io.Writer) error {
+	fs := newFlagSet("lint")
+	bundle := fs.String("bundle", "", "the action bundle (default the .lbaction in the current directory)")
+	script := fs.String("script", actionScript, "the actionDefaultScript of the action config")
+	feed := fs.String("feed", "", "the update feed file or url to check, e.g. the LBUpdate url")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	b, err := findBundle(*bundle)
+	if err != nil {
+		return err
+	}
+
+	issues, err := launchbar.LintBundle(b, *script)
+	if err != nil {
+		return err
+	}
+	if *feed != "" {
+		info, err := launchbar.ReadInfoPlist(infoPlistPath(b))
+		if err != nil {
+			return err
+		}
+		data, err := readFeed(*feed)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, launchbar.LintUpdateFeed(info, data)...)
+	}
+
+	for _, i := range issues {
+		fmt.Fprintln(stdout, i)
+	}
+	if n := launchbar.LintErrors(issues); n > 0 {
+		return fmt.Errorf("%s: %d errors, %d warnings", b, n, len(issues)-n)
+	}
+	return nil
+}
+
+// feedClient fetches the update feeds, it gives up on a server that does not
+// respond instead of hanging.
+var feedClient = &launchbar.HTTPClient{
+	Client:    &http.Client{Timeout: 30 * time.Second},
+	UserAgent: "lbaction go-launchbar",
+	Retries:   2,
+	Backoff:   500 * time.Millisecond,
+}
+
+// readFeed reads the update feed from the file or the http(s) url.
+func readFeed(feed string) ([]byte, error) {
+	if !strings.HasPrefix(feed, "http://") && !strings.HasPrefix(feed, "https://") {
+		return ioutil.ReadFile(feed)
+	}
+	resp, err := feedClient.Get(feed)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", feed, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
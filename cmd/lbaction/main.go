@@ -0,0 +1,103 @@
+// Command lbaction creates, builds and releases LaunchBar actions written with
+// go-launchbar.
+//
+// Usage:
+//
+//	lbaction init [-id identifier] [-author name] [-dir dir] Name
+//	lbaction build [-bundle Name.lbaction] [-arch amd64,arm64] [package]
+//	lbaction bump [-bundle Name.lbaction] [major|minor|patch|version]
+//	lbaction feed [-bundle Name.lbaction] [-download url] [-changelog file] [-o update.plist]
+//	lbaction lint [-bundle Name.lbaction] [-script name] [-feed file|url]
+//
+// init creates the action bundle and a Go main package, build cross-compiles
+// the package into the bundle, bump increases CFBundleVersion, feed writes
+// the update plist that is served at LBDescription.LBUpdate and lint checks
+// the bundle and the update feed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand of lbaction.
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands map[string]command
+
+func init() {
+	// set in init, the commands use newFlagSet that refers to commands
+	commands = map[string]command{
+		"init":  {"init [-id identifier] [-author name] [-dir dir] Name", runInit},
+		"build": {"build [-bundle Name.lbaction] [-arch amd64,arm64] [package]", runBuild},
+		"bump":  {"bump [-bundle Name.lbaction] [major|minor|patch|version]", runBump},
+		"feed":  {"feed [-bundle Name.lbaction] [-download url] [-changelog file] [-o update.plist]", runFeed},
+		"lint":  {"lint [-bundle Name.lbaction] [-script name] [-feed file|url]", runLint},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  lbaction", commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	if err := cmd.run(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "lbaction %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet returns the flag set of the subcommand name.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: lbaction", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// findBundle returns bundle, or the only .lbaction bundle in the current
+// directory if it's empty.
+func findBundle(bundle string) (string, error) {
+	if bundle != "" {
+		if !strings.HasSuffix(bundle, ".lbaction") {
+			return "", fmt.Errorf("%s is not an .lbaction bundle", bundle)
+		}
+		return bundle, nil
+	}
+	matches, _ := filepath.Glob("*.lbaction")
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no .lbaction bundle in the current directory, use -bundle")
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("more than one .lbaction bundle in the current directory, use -bundle")
+}
+
+func infoPlistPath(bundle string) string { return filepath.Join(bundle, "Contents", "Info.plist") }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.test</string>
+	<key>CFBundleName</key>
+	<string>Test</string>
+	<key>CFBundleVersion</key>
+	<string>1.0</string>
+	<key>LBScripts</key>
+	<dict>
+		<key>LBDefaultScript</key>
+		<dict>
+			<key>LBScriptName</key>
+			<string>default.sh</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+// The test binary is the action when testAction is set.
+const testAction = "LBSIM_TEST_ACTION"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testAction) == "1" {
+		runTestAction()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func runTestAction() {
+	a := launchbar.NewAction("Test", launchbar.ConfigValues{"actionDefaultScript": "default.sh", "autoUpdate": false, "notifications": true})
+
+	main := a.NewView("main")
+	main.NewItem("greet").Run("greet", "Sam")
+	main.NewItem("other").SetRun(func(c *launchbar.Context) {
+		c.Action.ShowView("other")
+	})
+	main.NewItem("parent").SetChildren(launchbar.NewItems().
+		Add(launchbar.NewItem("child 1")).
+		Add(launchbar.NewItem("child 2")))
+	main.NewItem("shift").SetMatch(func(c *launchbar.Context) bool { return c.Action.IsShiftKey() })
+	main.NewItem("forget").Run("forget")
+
+	a.NewView("other").NewItem("in other")
+
+	a.Init(launchbar.FuncMap{
+		"greet": func(c *launchbar.Context, name string) {
+			c.Notify("Hello", name)
+		},
+		"forget": func(c *launchbar.Context) {
+			c.Cache.Set("seen", true, time.Hour)
+			c.Cache.Delete("seen")
+			c.Notify("Forgot", "seen")
+		},
+	})
+	fmt.Println(a.Run())
+}
+
+func newTestSimulator(t *testing.T) (*simulator, *bytes.Buffer) {
+	dir, err := ioutil.TempDir("", "lbsim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	bundle := filepath.Join(dir, "Test.lbaction")
+	os.MkdirAll(filepath.Join(bundle, "Contents", "Scripts"), 0755)
+	if err := ioutil.WriteFile(filepath.Join(bundle, "Contents", "Info.plist"), []byte(testInfoPlist), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(testAction, "1")
+
+	var out bytes.Buffer
+	s, err := newSimulator(bundle, filepath.Join(dir, "state"), []string{os.Args[0]}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, &out
+}
+
+func TestSimulator(t *testing.T) {
+	s, out := newTestSimulator(t)
+	repl(s, strings.NewReader(strings.Join([]string{
+		":>3",
+		":<",
+		":1",
+		":2",
+		":9",
+		":bogus",
+		":q",
+		"not read",
+	}, "\n")))
+
+	for _, want := range []string{
+		"1  greet",
+		"1  child 1",
+		"notification: Hello: Sam",
+		"↻ rerun",
+		"in other",
+		"error: no item 9",
+		`error: unknown command ":bogus"`,
+		"Test> ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%q is not in the output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "not read") {
+		t.Errorf("the input after :q was read")
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	s, out := newTestSimulator(t)
+	if err := s.run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "shift") {
+		t.Errorf("the shift item is shown without shift:\n%s", out)
+	}
+
+	out.Reset()
+	if err := s.do(":shift"); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.prompt(); p != "⇧ Test> " {
+		t.Errorf("prompt = %q", p)
+	}
+	if err := s.do(""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "shift") {
+		t.Errorf("the shift item is not shown with shift:\n%s", out)
+	}
+	if err := s.do(":meta"); err == nil {
+		t.Errorf(":meta did not fail")
+	}
+}
+
+func TestSimulatorCache(t *testing.T) {
+	s, out := newTestSimulator(t)
+	if err := s.run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.pick(4); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if !strings.Contains(out.String(), "notification: Forgot: seen") {
+		t.Errorf("the cache delete failed:\n%s", out)
+	}
+}
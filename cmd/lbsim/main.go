@@ -0,0 +1,143 @@
+// Command lbsim runs a LaunchBar action in the terminal, outside LaunchBar.
+//
+// Usage:
+//
+//	lbsim [-bundle Name.lbaction] [-state dir] [command [args...]]
+//
+// The command is run like LaunchBar runs the default script of the bundle:
+// with the bundle environment (LB_ACTION_PATH, LB_CACHE_PATH, ...) and the
+// input or the json of the picked item as the last argument. It defaults to
+// the LBDefaultScript of the bundle; on other platforms than macOS give the
+// binary built for it, e.g.
+//
+//	go build -o /tmp/action . && lbsim /tmp/action
+//
+// Type a line to run the action with it as the input, an empty line runs it
+// without input. The items are shown as a table, the lines starting with a
+// colon are commands:
+//
+//	:N            pick the item N, like Enter in LaunchBar
+//	:>N           show the children of the item N
+//	:<            go back from the children
+//	:shift :alt :cmd :ctrl
+//	              toggle the modifier key for the next runs
+//	:r            run again with the last input
+//	:q            quit
+//
+// The reruns the action requests (ShowView, Push, Pop, ...) are followed, its
+// notifications, large type, clipboard and paste calls are printed.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const help = `commands:
+  text           run the action with the input, empty runs it without input
+  :N             pick the item N
+  :>N            show the children of the item N
+  :<             go back from the children
+  :shift :alt :cmd :ctrl
+                 toggle the modifier key
+  :r             run again with the last input
+  :q             quit`
+
+func main() {
+	bundle := flag.String("bundle", "", "the action bundle (default the .lbaction in the current directory)")
+	state := flag.String("state", "", "the directory of the cache, support and home directories (default a temporary one)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: lbsim [-bundle Name.lbaction] [-state dir] [command [args...]]")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr, help)
+	}
+	flag.Parse()
+
+	if *bundle == "" {
+		matches, _ := filepath.Glob("*.lbaction")
+		if len(matches) != 1 {
+			fmt.Fprintln(os.Stderr, "lbsim: cannot find the action bundle, use -bundle")
+			os.Exit(2)
+		}
+		*bundle = matches[0]
+	}
+	if *state == "" {
+		dir, err := ioutil.TempDir("", "lbsim")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lbsim:", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+		*state = dir
+	}
+
+	s, err := newSimulator(*bundle, *state, flag.Args(), os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lbsim:", err)
+		os.Exit(1)
+	}
+	repl(s, os.Stdin)
+}
+
+// repl reads the input and the commands until :q or the end of in.
+func repl(s *simulator, in io.Reader) {
+	if err := s.run(nil); err != nil {
+		fmt.Fprintln(s.out, "error:", err)
+	}
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, s.prompt())
+		if !sc.Scan() {
+			fmt.Fprintln(s.out)
+			return
+		}
+		line := sc.Text()
+		if line == ":q" {
+			return
+		}
+		if err := s.do(line); err != nil {
+			fmt.Fprintln(s.out, "error:", err)
+		}
+	}
+}
+
+// do runs the input line or the command.
+func (s *simulator) do(line string) error {
+	if line == "" {
+		return s.run(nil)
+	}
+	if !strings.HasPrefix(line, ":") {
+		return s.run(&line)
+	}
+
+	cmd := strings.TrimSpace(line[1:])
+	switch {
+	case cmd == "r":
+		return s.run(s.input)
+	case cmd == "<":
+		return s.back()
+	case strings.HasPrefix(cmd, ">"):
+		n, err := strconv.Atoi(strings.TrimSpace(cmd[1:]))
+		if err != nil {
+			return fmt.Errorf("bad item number %q", cmd[1:])
+		}
+		return s.enter(n)
+	case cmd == "help" || cmd == "h" || cmd == "?":
+		fmt.Fprintln(s.out, help)
+		return nil
+	}
+	if n, err := strconv.Atoi(cmd); err == nil {
+		return s.pick(n)
+	}
+	if err := s.toggle(cmd); err != nil {
+		return fmt.Errorf("unknown command %q, see :help", line)
+	}
+	return nil
+}
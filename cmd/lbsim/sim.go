@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	launchbar "github.com/nbjahan/go-launchbar"
+)
+
+// maxFollow limits how many reruns (ShowView, Push, ...) of a run are
+// followed, a rerunning action would loop forever otherwise.
+const maxFollow = 5
+
+// modifiers are the modifier keys that can be toggled and their environment
+// variables.
+var modifiers = []struct{ name, alias, symbol, env string }{
+	{"shift", "shift", "⇧", "LB_OPTION_SHIFT_KEY"},
+	{"option", "alt", "⌥", "LB_OPTION_ALTERNATE_KEY"},
+	{"command", "cmd", "⌘", "LB_OPTION_COMMAND_KEY"},
+	{"control", "ctrl", "⌃", "LB_OPTION_CONTROL_KEY"},
+}
+
+// simItem is an item of the action output, raw is passed back to the action
+// when the item is picked.
+type simItem struct {
+	Title                  string            `json:"title"`
+	Subtitle               string            `json:"subtitle"`
+	URL                    string            `json:"url"`
+	Path                   string            `json:"path"`
+	Action                 string            `json:"action"`
+	ActionArgument         string            `json:"actionArgument"`
+	ActionReturnsItems     bool              `json:"actionReturnsItems"`
+	ActionRunsInBackground bool              `json:"actionRunsInBackground"`
+	Children               []json.RawMessage `json:"children"`
+	Func                   string            `json:"x-func"`
+
+	raw json.RawMessage
+}
+
+// parseItems parses the json output of the action.
+func parseItems(raw []json.RawMessage) ([]*simItem, error) {
+	items := make([]*simItem, 0, len(raw))
+	for _, r := range raw {
+		i := &simItem{raw: r}
+		if err := json.Unmarshal(r, i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+// simulator runs the action command in a fake LaunchBar environment.
+type simulator struct {
+	command []string // the action script, run with the input as argument
+	bundle  string   // the absolute path of the .lbaction bundle
+	state   string   // home, cache and support directories
+	info    *launchbar.InfoPlist
+	out     io.Writer
+
+	mods   map[string]bool
+	items  []*simItem   // the items shown
+	parent [][]*simItem // the items the children were entered from
+	input  *string      // the input of the last run
+}
+
+func newSimulator(bundle, state string, command []string, out io.Writer) (*simulator, error) {
+	bundle, err := filepath.Abs(bundle)
+	if err != nil {
+		return nil, err
+	}
+	info, err := launchbar.ReadInfoPlist(filepath.Join(bundle, "Contents", "Info.plist"))
+	if err != nil {
+		return nil, err
+	}
+	if err := info.Validate(); err != nil {
+		fmt.Fprintln(out, "warning:", err)
+	}
+	if len(command) == 0 {
+		if info.LBScripts.LBDefaultScript == nil {
+			return nil, fmt.Errorf("no LBDefaultScript, give the action command")
+		}
+		command = []string{filepath.Join(bundle, "Contents", "Scripts", info.LBScripts.LBDefaultScript.LBScriptName)}
+	}
+	s := &simulator{command: command, bundle: bundle, state: state, info: info, out: out, mods: map[string]bool{}}
+	for _, d := range []string{s.cachePath(), s.supportPath()} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// cachePath and supportPath are under the fake home like in LaunchBar, Cache
+// and Config refuse to delete or save anywhere else.
+func (s *simulator) cachePath() string {
+	return filepath.Join(s.state, "home", "Library", "Caches", "at.obdev.LaunchBar", "Actions", s.info.CFBundleIdentifier)
+}
+
+func (s *simulator) supportPath() string {
+	return filepath.Join(s.state, "home", "Library", "Application Support", "LaunchBar", "Action Support", s.info.CFBundleIdentifier)
+}
+
+func (s *simulator) callsPath() string { return filepath.Join(s.state, "calls") }
+
+// env returns the environment LaunchBar passes to the action.
+func (s *simulator) env() []string {
+	env := append(os.Environ(),
+		"HOME="+filepath.Join(s.state, "home"),
+		"LB_ACTION_PATH="+s.bundle,
+		"LB_CACHE_PATH="+s.cachePath(),
+		"LB_SUPPORT_PATH="+s.supportPath(),
+		"LB_SCRIPT_TYPE=default",
+		launchbar.SimulatorEnv+"="+s.callsPath(),
+	)
+	for _, m := range modifiers {
+		v := "0"
+		if s.mods[m.name] {
+			v = "1"
+		}
+		env = append(env, m.env+"="+v)
+	}
+	return env
+}
+
+// exec runs the action with the argument, nil runs it without input, and
+// returns its output and the LaunchBar calls it made.
+func (s *simulator) exec(arg *string) ([]byte, []launchbar.ClientCall, error) {
+	os.Remove(s.callsPath())
+	args := append([]string{}, s.command[1:]...)
+	if arg != nil {
+		args = append(args, *arg)
+	}
+	cmd := exec.Command(s.command[0], args...)
+	cmd.Env = s.env()
+	cmd.Stderr = s.out
+	out, err := cmd.Output()
+	if err != nil {
+		return out, nil, err
+	}
+
+	var calls []launchbar.ClientCall
+	if data, err := ioutil.ReadFile(s.callsPath()); err == nil {
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for sc.Scan() {
+			var c launchbar.ClientCall
+			if json.Unmarshal(sc.Bytes(), &c) == nil {
+				calls = append(calls, c)
+			}
+		}
+	}
+	return out, calls, nil
+}
+
+// run runs the action with the input and shows its items. The reruns it
+// requests, e.g. ShowView, are followed.
+func (s *simulator) run(arg *string) error {
+	for n := 0; ; n++ {
+		out, calls, err := s.exec(arg)
+		if err != nil {
+			return err
+		}
+		s.input = arg
+		if err := s.show(out); err != nil {
+			return err
+		}
+		again, rerun := s.printCalls(calls)
+		if !again {
+			return nil
+		}
+		if n == maxFollow {
+			return fmt.Errorf("the action reran itself more than %d times", maxFollow)
+		}
+		arg = rerun
+	}
+}
+
+// show shows the output of the action, items if it's json.
+func (s *simulator) show(out []byte) error {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(out, &raw); err != nil {
+		fmt.Fprintf(s.out, "%s\n", out)
+		return nil
+	}
+	items, err := parseItems(raw)
+	if err != nil {
+		return err
+	}
+	s.items, s.parent = items, nil
+	s.printItems()
+	return nil
+}
+
+// printCalls prints the LaunchBar calls. It returns true if the action asked
+// to be run again and the argument of the run, nil runs it without input.
+func (s *simulator) printCalls(calls []launchbar.ClientCall) (bool, *string) {
+	again := false
+	var arg *string
+	for _, c := range calls {
+		switch c.Method {
+		case "PerformAction":
+			again, arg = true, nil
+			if len(c.Args) > 1 && c.Args[1] != "" {
+				a := c.Args[1]
+				arg = &a
+			}
+			fmt.Fprintln(s.out, "↻ rerun")
+		case "ShowNotification":
+			fmt.Fprintf(s.out, "notification: %s\n", strings.Join(c.Args, ": "))
+		case "DisplayText":
+			fmt.Fprintf(s.out, "large type: %s\n", strings.Join(c.Args, ""))
+		default:
+			fmt.Fprintf(s.out, "%s %q\n", c.Method, c.Args)
+		}
+	}
+	return again, arg
+}
+
+// printItems prints the items as a table.
+func (s *simulator) printItems() {
+	if len(s.items) == 0 {
+		fmt.Fprintln(s.out, "(no items)")
+		return
+	}
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for n, i := range s.items {
+		var flags []string
+		switch {
+		case len(i.Children) > 0:
+			flags = append(flags, fmt.Sprintf("▸ %d", len(i.Children)))
+		case i.URL != "":
+			flags = append(flags, i.URL)
+		case i.Path != "":
+			flags = append(flags, i.Path)
+		}
+		if i.Func != "" {
+			flags = append(flags, "func "+i.Func)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", n+1, i.Title, i.Subtitle, strings.Join(flags, " "))
+	}
+	w.Flush()
+}
+
+// pick selects the item n (1-based) like pressing Enter in LaunchBar: the
+// item is passed back to the action as json, or its url or path is opened.
+func (s *simulator) pick(n int) error {
+	if n < 1 || n > len(s.items) {
+		return fmt.Errorf("no item %d", n)
+	}
+	i := s.items[n-1]
+	switch {
+	case i.Action != "":
+		arg := string(i.raw)
+		if i.ActionArgument != "" {
+			arg = i.ActionArgument
+		}
+		if i.ActionRunsInBackground {
+			fmt.Fprintf(s.out, "running %s in the background\n", i.Action)
+			_, calls, err := s.exec(&arg)
+			if err != nil {
+				return err
+			}
+			if again, rerun := s.printCalls(calls); again {
+				return s.run(rerun)
+			}
+			return nil
+		}
+		return s.run(&arg)
+	case i.URL != "":
+		fmt.Fprintln(s.out, "open", i.URL)
+	case i.Path != "":
+		fmt.Fprintln(s.out, "open", i.Path)
+	case len(i.Children) > 0:
+		return s.enter(n)
+	default:
+		fmt.Fprintln(s.out, "the item has no action")
+	}
+	return nil
+}
+
+// enter shows the children of the item n (1-based), like → in LaunchBar.
+func (s *simulator) enter(n int) error {
+	if n < 1 || n > len(s.items) {
+		return fmt.Errorf("no item %d", n)
+	}
+	children, err := parseItems(s.items[n-1].Children)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return fmt.Errorf("item %d has no children", n)
+	}
+	s.parent = append(s.parent, s.items)
+	s.items = children
+	s.printItems()
+	return nil
+}
+
+// back leaves the children, like ← in LaunchBar.
+func (s *simulator) back() error {
+	if len(s.parent) == 0 {
+		return fmt.Errorf("not in the children of an item")
+	}
+	s.items = s.parent[len(s.parent)-1]
+	s.parent = s.parent[:len(s.parent)-1]
+	s.printItems()
+	return nil
+}
+
+// toggle toggles the modifier key.
+func (s *simulator) toggle(name string) error {
+	for _, m := range modifiers {
+		if m.name == name || m.alias == name {
+			s.mods[m.name] = !s.mods[m.name]
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown modifier %q", name)
+}
+
+// prompt returns the prompt with the modifiers that are down.
+func (s *simulator) prompt() string {
+	var b strings.Builder
+	for _, m := range modifiers {
+		if s.mods[m.name] {
+			b.WriteString(m.symbol)
+		}
+	}
+	if len(s.parent) > 0 {
+		b.WriteString(strings.Repeat("▸", len(s.parent)))
+	}
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	return b.String() + s.info.CFBundleName + "> "
+}
@@ -0,0 +1,161 @@
+package launchbar
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Command represents a mini command typed into LaunchBar, e.g.
+//
+//	add "oat milk" #groceries due:tomorrow
+//
+// is parsed as Name: "add", Args: ["oat milk"], Params: {"due": "tomorrow"},
+// Tags: ["groceries"].
+type Command struct {
+	Name   string
+	Args   []string
+	Params map[string]string
+	Tags   []string
+	Raw    string
+}
+
+// Arg returns the nth positional argument or "" if there's no such argument.
+func (c *Command) Arg(n int) string {
+	if n < 0 || n >= len(c.Args) {
+		return ""
+	}
+	return c.Args[n]
+}
+
+// Param returns the value of a key:value pair or "" if the key is not present.
+func (c *Command) Param(key string) string { return c.Params[key] }
+
+// HasTag returns true if the command has the #tag.
+func (c *Command) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCommand splits s into a command name, positional arguments, key:value
+// pairs and #tags. Double and single quotes group words, a backslash escapes
+// the next character. A quoted token is always a positional argument.
+func ParseCommand(s string) *Command {
+	cmd := &Command{Params: make(map[string]string), Raw: s}
+	for i, t := range tokenize(s) {
+		switch {
+		case t.quoted:
+			cmd.Args = append(cmd.Args, t.text)
+		case strings.HasPrefix(t.text, "#") && len(t.text) > 1:
+			cmd.Tags = append(cmd.Tags, t.text[1:])
+		case strings.Index(t.text, ":") > 0 && !strings.Contains(t.text, "://"):
+			kv := strings.SplitN(t.text, ":", 2)
+			cmd.Params[kv[0]] = kv[1]
+		case i == 0:
+			cmd.Name = t.text
+		default:
+			cmd.Args = append(cmd.Args, t.text)
+		}
+	}
+	return cmd
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(s string) []token {
+	var tokens []token
+	var b strings.Builder
+	var quote rune
+	inToken, quoted, escaped := false, false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inToken = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, quoted, inToken = r, true, true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, token{b.String(), quoted})
+				b.Reset()
+				inToken, quoted = false, false
+			}
+		default:
+			b.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, token{b.String(), quoted})
+	}
+	return tokens
+}
+
+// CommandSpec declares a command that the action routes to a view or to a
+// FuncMap entry.
+type CommandSpec struct {
+	Name        string
+	Usage       string // the arguments the command expects, e.g. "<item> [#tag] [due:date]"
+	Description string
+	View        string
+	Func        string
+
+	args   []*argSpec
+	params []*argSpec
+	tags   *argSpec
+}
+
+// SetUsage sets the description of the arguments shown in the completion items.
+func (s *CommandSpec) SetUsage(usage string) *CommandSpec { s.Usage = usage; return s }
+
+// SetDescription sets the description shown in the completion items.
+func (s *CommandSpec) SetDescription(desc string) *CommandSpec { s.Description = desc; return s }
+
+// SetView routes the command to the view.
+func (s *CommandSpec) SetView(view string) *CommandSpec { s.View = view; s.Func = ""; return s }
+
+// SetFunc routes the command to the FuncMap entry. The func is invoked with
+// the Context and its output is handled the same way as an Item.Run func.
+func (s *CommandSpec) SetFunc(name string) *CommandSpec { s.Func = name; s.View = ""; return s }
+
+// NewCommand declares a command. When the first word of the input is name,
+// the input is parsed with ParseCommand and routed to the view or func of the
+// command with the parsed Command available as Context.Command.
+//
+// Example:
+//
+//	a.NewCommand("add").SetUsage("<item> [#tag] [due:date]").SetFunc("add")
+func (a *Action) NewCommand(name string) *CommandSpec {
+	s := &CommandSpec{Name: name}
+	if a.commands == nil {
+		a.commands = make(map[string]*CommandSpec)
+	}
+	a.commands[name] = s
+	return s
+}
+
+// GetCommand returns the declared command or nil.
+func (a *Action) GetCommand(name string) *CommandSpec { return a.commands[name] }
+
+// commandSpecs returns the declared commands sorted by name.
+func (a *Action) commandSpecs() []*CommandSpec {
+	specs := make([]*CommandSpec, 0, len(a.commands))
+	for _, s := range a.commands {
+		specs = append(specs, s)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
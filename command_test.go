@@ -0,0 +1,74 @@
+package launchbar
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		in  string
+		out Command
+	}{
+		{"", Command{Params: map[string]string{}}},
+		{"add milk #groceries due:tomorrow", Command{
+			Name:   "add",
+			Args:   []string{"milk"},
+			Params: map[string]string{"due": "tomorrow"},
+			Tags:   []string{"groceries"},
+		}},
+		{`add "oat milk" 'due:today' a\ b`, Command{
+			Name:   "add",
+			Args:   []string{"oat milk", "due:today", "a b"},
+			Params: map[string]string{},
+		}},
+		{"open https://example.com", Command{
+			Name:   "open",
+			Args:   []string{"https://example.com"},
+			Params: map[string]string{},
+		}},
+		{`"quoted" name`, Command{
+			Args:   []string{"quoted", "name"},
+			Params: map[string]string{},
+		}},
+	}
+	for _, test := range tests {
+		cmd := ParseCommand(test.in)
+		test.out.Raw = test.in
+		if !reflect.DeepEqual(*cmd, test.out) {
+			t.Errorf("ParseCommand(%q) = %#v, want %#v", test.in, *cmd, test.out)
+		}
+	}
+}
+
+func TestCommandRouting(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"list", `"title":"listed"`},
+		{"show", `view \"missing\" is not defined`},
+		{"add milk", `unknown func \"add\"`},
+		{"fail", `command failed`},
+	} {
+		a := newTestAction(t, []string{test.in}, FuncMap{
+			"list": func() string { return NewItems().Add(NewItem("listed")).Compile() },
+			"fail": func() string { return "" },
+		})
+		a.Use(func(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+			if call.Name == "fail" {
+				return nil, errors.New("command failed")
+			}
+			return next()
+		})
+		a.NewView("main")
+		a.NewCommand("list").SetFunc("list")
+		a.NewCommand("show").SetView("missing")
+		a.NewCommand("add").SetFunc("add")
+		a.NewCommand("fail").SetFunc("fail")
+		if out := a.Run(); !strings.Contains(out, test.want) {
+			t.Errorf("Run(%q) = %s, want %s", test.in, out, test.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package launchbar
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/codegangsta/inject"
+)
+
+// argSpec declares a positional argument, a key:value param or the #tags of a
+// command. Values are the enumerated values, complete is an optional func
+// that returns more values at render time.
+type argSpec struct {
+	name     string
+	values   []string
+	complete Func
+}
+
+// Arg declares the next positional argument of the command with its
+// enumerated values.
+func (s *CommandSpec) Arg(name string, values ...string) *CommandSpec {
+	s.args = append(s.args, &argSpec{name: name, values: values})
+	return s
+}
+
+// ArgFunc declares the next positional argument of the command with a
+// completion func. The func is invoked like a Renderer func with
+// Context.Command set to the input parsed so far and must return a []string.
+//
+// Example:
+//
+//	func(c *Context) []string { return loadLists(c) }
+func (s *CommandSpec) ArgFunc(name string, fn Func) *CommandSpec {
+	s.args = append(s.args, &argSpec{name: name, complete: fn})
+	return s
+}
+
+// Param declares a key:value param of the command with its enumerated values.
+func (s *CommandSpec) Param(key string, values ...string) *CommandSpec {
+	s.params = append(s.params, &argSpec{name: key, values: values})
+	return s
+}
+
+// ParamFunc declares a key:value param of the command with a completion func
+// (see ArgFunc).
+func (s *CommandSpec) ParamFunc(key string, fn Func) *CommandSpec {
+	s.params = append(s.params, &argSpec{name: key, complete: fn})
+	return s
+}
+
+// Tags declares the #tags that the command accepts.
+func (s *CommandSpec) Tags(values ...string) *CommandSpec {
+	if s.tags == nil {
+		s.tags = &argSpec{name: "#tag"}
+	}
+	s.tags.values = append(s.tags.values, values...)
+	return s
+}
+
+// TagsFunc declares a completion func for the #tags (see ArgFunc).
+func (s *CommandSpec) TagsFunc(fn Func) *CommandSpec {
+	if s.tags == nil {
+		s.tags = &argSpec{name: "#tag"}
+	}
+	s.tags.complete = fn
+	return s
+}
+
+func (s *CommandSpec) param(key string) *argSpec {
+	for _, p := range s.params {
+		if p.name == key {
+			return p
+		}
+	}
+	return nil
+}
+
+// candidates returns the enumerated and the completed values of the arg for
+// the input cmd parsed so far.
+func (arg *argSpec) candidates(a *Action, cmd *Command) []string {
+	values := append([]string(nil), arg.values...)
+	if arg.complete == nil {
+		return values
+	}
+	vals, err := a.invoke(&Call{Kind: CallComplete, Name: arg.name, Func: arg.complete, Command: cmd}, func(inj inject.Injector) ([]reflect.Value, error) {
+		return inj.Invoke(arg.complete)
+	})
+	if err != nil {
+		a.Log.Error("completion func failed", "arg", arg.name, "error", err)
+		return values
+	}
+	if len(vals) > 0 {
+		if more, ok := vals[0].Interface().([]string); ok {
+			values = append(values, more...)
+		}
+	}
+	return values
+}
+
+// Completions returns the completion items for the input s based on the
+// declared commands. Each item reruns the action with the completed input as
+// the argument.
+func (a *Action) Completions(s string) *Items {
+	items := NewItems()
+	if len(a.commands) == 0 {
+		return items
+	}
+
+	// split s into the completed part and the token being typed
+	cut, quoted := lastToken(s)
+	if quoted {
+		// inside a quoted argument
+		return items
+	}
+	head, partial := s[:cut], s[cut:]
+	cmd := ParseCommand(head)
+	// typed is the token being typed without its quotes and escapes
+	typed := partial
+	if t := tokenize(partial); len(t) == 1 {
+		typed = t[0].text
+	}
+
+	// add adds the completion of the token being typed to token, the title of
+	// the item is value.
+	add := func(value, token, subtitle, suffix string) {
+		items.Add(NewItem(value).
+			SetSubtitle(subtitle).
+			SetIcon("at.obdev.LaunchBar:ActionTemplate").
+			SetAction(a.Config.GetString("actionDefaultScript")).
+			SetActionArgument(head + token + suffix).
+			SetActionReturnsItems(true))
+	}
+
+	if cmd.Name == "" && len(cmd.Args) == 0 && len(cmd.Params) == 0 && len(cmd.Tags) == 0 {
+		for _, spec := range a.commandSpecs() {
+			if !strings.HasPrefix(spec.Name, partial) || spec.Name == partial {
+				continue
+			}
+			subtitle := spec.Description
+			if spec.Usage != "" {
+				subtitle = strings.TrimSpace(spec.Usage + "  " + subtitle)
+			}
+			add(spec.Name, spec.Name, subtitle, " ")
+		}
+		return items
+	}
+
+	spec := a.commands[cmd.Name]
+	if spec == nil {
+		return items
+	}
+
+	switch {
+	case strings.HasPrefix(partial, "#"):
+		if spec.tags != nil {
+			for _, v := range spec.tags.candidates(a, cmd) {
+				if strings.HasPrefix(v, typed[1:]) && !cmd.HasTag(v) {
+					add("#"+v, "#"+escapeToken(v), spec.tags.name, " ")
+				}
+			}
+		}
+	case strings.Contains(partial, ":"):
+		kv := strings.SplitN(typed, ":", 2)
+		if p := spec.param(kv[0]); p != nil {
+			for _, v := range p.candidates(a, cmd) {
+				if strings.HasPrefix(v, kv[1]) && v != kv[1] {
+					add(kv[0]+":"+v, kv[0]+":"+escapeToken(v), p.name, " ")
+				}
+			}
+		}
+	default:
+		if n := len(cmd.Args); n < len(spec.args) {
+			arg := spec.args[n]
+			for _, v := range arg.candidates(a, cmd) {
+				if strings.HasPrefix(v, typed) && v != typed {
+					add(v, quoteArg(v), arg.name, " ")
+				}
+			}
+		}
+		for _, p := range spec.params {
+			if _, used := cmd.Params[p.name]; !used && strings.HasPrefix(p.name, typed) {
+				add(p.name+":", p.name+":", "", "")
+			}
+		}
+	}
+	return items
+}
+
+// lastToken returns the start of the last token of the input s and whether
+// s ends inside a quoted argument. The escaped spaces don't split tokens.
+func lastToken(s string) (start int, quoted bool) {
+	var quote rune
+	escaped := false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	return start, quote != 0
+}
+
+// quoteArg quotes the positional argument v when ParseCommand would not read
+// it back as is.
+func quoteArg(v string) string {
+	if !strings.ContainsAny(v, " \t\"'\\:") && !strings.HasPrefix(v, "#") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(v) + `"`
+}
+
+// escapeToken escapes the spaces, the quotes and the backslashes of the value
+// of a #tag or a key:value param, which can't be quoted.
+func escapeToken(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		if unicode.IsSpace(r) || r == '"' || r == '\'' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
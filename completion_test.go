@@ -0,0 +1,138 @@
+package launchbar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func completionArgs(items *Items) []string {
+	var out []string
+	for _, i := range *items {
+		out = append(out, i.item.ActionArgument)
+	}
+	return out
+}
+
+func TestCompletions(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.NewCommand("add").
+		Arg("list", "groceries", "work").
+		Param("due", "today", "tomorrow").
+		TagsFunc(func(c *Context) []string { return []string{"home", "urgent"} })
+	a.NewCommand("archive")
+	a.NewCommand("done")
+
+	tests := []struct {
+		in  string
+		out []string
+	}{
+		{"", []string{"add ", "archive ", "done "}},
+		{"a", []string{"add ", "archive "}},
+		{"add", nil},
+		{"add ", []string{"add groceries ", "add work ", "add due:"}},
+		{"add w", []string{"add work "}},
+		{"add work d", []string{"add work due:"}},
+		{"add work due:to", []string{"add work due:today ", "add work due:tomorrow "}},
+		{"add work #u", []string{"add work #urgent "}},
+		{"remove ", nil},
+		{`add "oat`, nil},
+	}
+	for _, test := range tests {
+		if out := completionArgs(a.Completions(test.in)); !reflect.DeepEqual(out, test.out) {
+			t.Errorf("Completions(%q) = %q, want %q", test.in, out, test.out)
+		}
+	}
+}
+
+func TestCompletionsRoundTrip(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.NewCommand("add").
+		Arg("list", "oat milk", `say "hi"`, "a:b", "#1").
+		Param("due", "next week").
+		Tags("a b", `c\d`)
+
+	tests := []struct {
+		in   string
+		want *Command
+	}{
+		{"add oat", &Command{Name: "add", Args: []string{"oat milk"}}},
+		{"add say", &Command{Name: "add", Args: []string{`say "hi"`}}},
+		{"add a", &Command{Name: "add", Args: []string{"a:b"}}},
+		{"add #", &Command{Name: "add", Tags: []string{"a b", `c\d`}}},
+		{"add due:n", &Command{Name: "add", Params: map[string]string{"due": "next week"}}},
+		{`add due:next\ w`, &Command{Name: "add", Params: map[string]string{"due": "next week"}}},
+		{`add #a\ `, &Command{Name: "add", Tags: []string{"a b"}}},
+	}
+	for _, test := range tests {
+		items := a.Completions(test.in)
+		if len(*items) == 0 {
+			t.Errorf("Completions(%q) is empty", test.in)
+			continue
+		}
+		for _, i := range *items {
+			got := ParseCommand(i.item.ActionArgument)
+			want := *test.want
+			if want.Params == nil {
+				want.Params = map[string]string{}
+			}
+			// the tags are completed one at a time
+			if len(want.Tags) > 1 {
+				want.Tags = []string{i.item.Title[1:]}
+			}
+			want.Raw = got.Raw
+			if !reflect.DeepEqual(got, &want) {
+				t.Errorf("ParseCommand(%q) = %+v, want %+v", i.item.ActionArgument, got, &want)
+			}
+		}
+	}
+}
+
+func TestCompletionsInRun(t *testing.T) {
+	var calls []*Call
+	var seen *Command
+	newAction := func(in string) *Action {
+		a := newTestAction(t, []string{in})
+		a.NewView("main").NewItem("item")
+		a.NewCommand("add").ArgFunc("list", func(c *Context) []string {
+			seen = c.Command
+			return []string{"work"}
+		})
+		a.Use(func(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+			calls = append(calls, call)
+			return next()
+		})
+		return a
+	}
+
+	a := newAction("add ")
+	if out := a.Run(); !strings.Contains(out, `"actionArgument":"add work "`) {
+		t.Errorf("Run() = %s, want the completions", out)
+	}
+	if seen == nil || seen.Name != "add" {
+		t.Errorf("the completion func got %v", seen)
+	}
+	var complete []string
+	for _, call := range calls {
+		if call.Kind == CallComplete {
+			complete = append(complete, call.Name)
+		}
+	}
+	if !reflect.DeepEqual(complete, []string{"list"}) {
+		t.Errorf("expected a single complete call got %v", complete)
+	}
+
+	a = newAction("add ")
+	a.Completions("add ")
+	if a.context.Command != nil {
+		t.Errorf("Completions changed the action context: %v", a.context.Command)
+	}
+	if out := a.GetView("main").Compile(); strings.Contains(out, "add work") {
+		t.Errorf("a view that is not the input view got the completions: %s", out)
+	}
+	t.Setenv("LB_OPTION_SHIFT_KEY", "1")
+	t.Setenv("LB_OPTION_COMMAND_KEY", "1")
+	if out := a.Run(); strings.Contains(out, "add work") {
+		t.Errorf("the diagnostics view got the completions: %s", out)
+	}
+}
@@ -1,13 +1,50 @@
 package launchbar
 
-import "log"
+import (
+	"context"
+	"log"
+)
 
 // Context is a dependency that is available in Matcher, Runner, Renderer func
 type Context struct {
-	Action *Action     // points to the LaunchBar action
-	Config *Config     // the Config object
-	Cache  *Cache      // the Cache object
-	Self   *Item       // the item that is accessing the context
-	Input  *Input      // the user input
-	Logger *log.Logger // Logger is used to log to Action.SupportPath() + '/error.log'
+	Action     *Action         // points to the LaunchBar action
+	Config     *Config         // the Config object
+	Cache      *Cache          // the Cache object
+	Self       *Item           // the item that is accessing the context
+	Input      *Input          // the user input
+	Command    *Command        // the parsed input when it's routed to a command (see Action.NewCommand)
+	ViewParams ViewParams      // the parameters of the current view (see Action.ShowViewWith)
+	Logger     *log.Logger     // Logger is used to log to Action.SupportPath() + '/error.log'
+	Log        *Logger         // the leveled logger behind Logger
+	HTTP       *HTTPClient     // the shared http client
+	Ctx        context.Context // carries the deadline of the current func, see TimeoutError
 }
+
+// Notify shows a transient message, e.g. "Copied!", in a notification. If
+// the notifications are turned off with the config key notifications, or the
+// notification cannot be shown, the message is returned as a single item
+// instead. Return the result from a Runner func:
+//
+//	func(c *Context) *Items { return c.Notify("Copied!", value) }
+func (c *Context) Notify(title, text string) *Items {
+	if c.Config.GetBool("notifications") {
+		err := c.Action.Client.ShowNotification(title, text)
+		if err == nil {
+			return nil
+		}
+		c.Log.Warn("cannot show the notification", "error", err)
+	}
+	return NewItems().Add(NewItem(title).SetSubtitle(text))
+}
+
+// LargeType displays text in large type.
+func (c *Context) LargeType(text string) error { return c.Action.Client.DisplayText(text) }
+
+// Copy copies text to the clipboard.
+func (c *Context) Copy(text string) error { return c.Action.Client.SetClipboard(text) }
+
+// Paste pastes text into the frontmost application.
+func (c *Context) Paste(text string) error { return c.Action.Client.Paste(text) }
+
+// OpenURL opens the url.
+func (c *Context) OpenURL(url string) error { return c.Action.Client.OpenURL(url) }
@@ -0,0 +1,58 @@
+package launchbar
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestContextNotify(t *testing.T) {
+	a := newTestAction(t, nil)
+	client := a.Client.(*RecordingClient)
+	c := a.context
+
+	if items := c.Notify("Copied!", "hello"); items != nil {
+		t.Errorf("Notify returned items %s, want nil", items.Compile())
+	}
+	want := []ClientCall{{"ShowNotification", []string{"Copied!", "hello"}}}
+	if calls := client.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	for name, setup := range map[string]func(){
+		"disabled": func() { a.Config.data["notifications"] = false },
+		"failed":   func() { client.Err = errors.New("no osascript") },
+	} {
+		client.Reset()
+		client.Err = nil
+		a.Config.data["notifications"] = true
+		setup()
+		items := c.Notify("Copied!", "hello")
+		if items == nil || len(items.getItems()) != 1 {
+			t.Fatalf("%s: Notify returned %v, want a single item", name, items)
+		}
+		if i := items.getItems()[0]; i.Title != "Copied!" || i.Subtitle != "hello" {
+			t.Errorf("%s: item = %q %q", name, i.Title, i.Subtitle)
+		}
+	}
+}
+
+func TestContextClientHelpers(t *testing.T) {
+	a := newTestAction(t, nil)
+	client := a.Client.(*RecordingClient)
+	c := a.context
+
+	c.Copy("a")
+	c.Paste("b")
+	c.LargeType("c")
+	c.OpenURL("https://example.com")
+	want := []ClientCall{
+		{"SetClipboard", []string{"a"}},
+		{"Paste", []string{"b"}},
+		{"DisplayText", []string{"c"}},
+		{"OpenURL", []string{"https://example.com"}},
+	}
+	if calls := client.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,296 @@
+package launchbar
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiagnosticsView is the name of the built-in diagnostics view. It's shown
+// when the action is run with Shift+Command held down or with ShowView.
+//
+// Define a view with the same name to replace it.
+const DiagnosticsView = "diagnostics"
+
+type diagnosticsSection struct {
+	Title string // the heading in the report, it's not translated
+	Icon  string
+	Lines [][2]string
+}
+
+// newDiagnosticsView creates the built-in diagnostics view.
+func (a *Action) newDiagnosticsView() *View {
+	v := a.NewView(DiagnosticsView)
+	v.NewItem("").
+		SetIcon("at.obdev.LaunchBar:InfoTemplate").
+		SetRender(func(c *Context) {
+			c.Self.SetTitle(fmt.Sprintf("%s v%s", c.Action.name, c.Action.Version()))
+			c.Self.SetSubtitle(c.Action.ActionPath())
+		})
+	v.NewItem(a.T("diagnostics.report")).
+		SetSubtitle(a.T("diagnostics.report.subtitle")).
+		SetIcon("at.obdev.LaunchBar:ActionTemplate").
+		SetActionRunsInBackground(false).
+		SetActionReturnsItems(true).
+		SetRun(func(c *Context) *Items {
+			p, err := writeDiagnosticsReport(c)
+			if err != nil {
+				c.Log.Error("cannot create diagnostics report", "error", err)
+				return NewItems().Add(NewItem(err.Error()).SetSubtitle(c.T("diagnostics.report.failed")))
+			}
+			return NewItems().Add(NewItem(path.Base(p)).SetSubtitle(c.T("diagnostics.report.created")).SetPath(p))
+		})
+	v.NewItem(a.T("diagnostics.log")).
+		SetIcon("at.obdev.LaunchBar:ContentsTemplate").
+		SetRender(func(c *Context) {
+			p := path.Join(c.Action.SupportPath(), "error.log")
+			lines, _ := tailLines(p, int(c.Config.GetInt("diagnosticsLogLines")))
+			c.Self.SetSubtitle(c.T("diagnostics.log.lines", len(lines)))
+			c.Self.SetQuickLookURL("file://" + p)
+			children := NewItems()
+			for i := len(lines) - 1; i >= 0; i-- {
+				children.Add(NewItem(redactText(lines[i], c.Config.data)))
+			}
+			c.Self.SetChildren(children)
+		})
+	for i, s := range []string{"environment", "config", "cache", "update"} {
+		n := i
+		v.NewItem(a.T("diagnostics." + s)).
+			SetIcon("at.obdev.LaunchBar:ContentsTemplate").
+			SetRender(func(c *Context) {
+				section := diagnosticsSections(c)[n]
+				children := NewItems()
+				for _, line := range section.Lines {
+					children.Add(NewItem(line[1]).SetSubtitle(line[0]))
+				}
+				c.Self.SetSubtitle(c.T("diagnostics.entries", len(section.Lines)))
+				c.Self.SetChildren(children)
+			})
+	}
+	return v
+}
+
+// diagnosticsSections collects the environment, config, cache and update state.
+func diagnosticsSections(c *Context) []diagnosticsSection {
+	a := c.Action
+
+	env := diagnosticsSection{Title: "Environment"}
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "LB_") {
+			parts := strings.SplitN(kv, "=", 2)
+			env.Lines = append(env.Lines, [2]string{parts[0], parts[1]})
+		}
+	}
+	sort.Slice(env.Lines, func(i, j int) bool { return env.Lines[i][0] < env.Lines[j][0] })
+
+	config := diagnosticsSection{Title: "Config"}
+	data := redactConfig(c.Config.data)
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := ""
+		if data[k] != nil {
+			v = fmt.Sprintf("%v", data[k])
+		}
+		config.Lines = append(config.Lines, [2]string{k, v})
+	}
+
+	cache := diagnosticsSection{Title: "Cache"}
+	if files, err := ioutil.ReadDir(a.CachePath()); err == nil {
+		var size int64
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			size += f.Size()
+			cache.Lines = append(cache.Lines, [2]string{f.Name(), fmt.Sprintf("%d bytes, modified %s", f.Size(), f.ModTime().Format(time.RFC3339))})
+		}
+		cache.Lines = append([][2]string{{"total", fmt.Sprintf("%d files, %d bytes", len(cache.Lines), size)}}, cache.Lines...)
+	} else {
+		cache.Lines = append(cache.Lines, [2]string{"error", err.Error()})
+	}
+
+	update := diagnosticsSection{Title: "Update"}
+	update.Lines = append(update.Lines, [2]string{"autoUpdate", fmt.Sprintf("%v", c.Config.GetBool("autoUpdate"))})
+	if s := a.info.LBDescription.LBUpdate; s != "" {
+		update.Lines = append(update.Lines, [2]string{"LBUpdate", s})
+	}
+	var lastUpdate, updateStartTime time.Time
+	if _, err := c.Cache.Get("lastUpdate", &lastUpdate); err == nil || err == ErrCacheIsExpired {
+		update.Lines = append(update.Lines, [2]string{"lastUpdate", lastUpdate.Format(time.RFC3339)})
+	}
+	if _, err := c.Cache.Get("updateStartTime", &updateStartTime); err == nil {
+		update.Lines = append(update.Lines, [2]string{"updateStartTime", updateStartTime.Format(time.RFC3339)})
+	}
+	var updateInfo map[string]string
+	if _, err := c.Cache.Get("updateInfo", &updateInfo); err == nil || err == ErrCacheIsExpired {
+		update.Lines = append(update.Lines,
+			[2]string{"remote version", updateInfo["version"]},
+			[2]string{"download", updateInfo["download"]})
+	}
+
+	return []diagnosticsSection{env, config, cache, update}
+}
+
+// reSecretKey matches the config keys that likely hold credentials.
+var reSecretKey = regexp.MustCompile(`(?i)token|key|secret|passw|credential`)
+
+// redactConfig returns a copy of the config data that is safe to share: the
+// values of the keys that look like credentials and the userinfo of the urls,
+// e.g. of a proxy, are replaced.
+func redactConfig(data map[string]interface{}) map[string]interface{} {
+	const redacted = "xxxxx"
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if reSecretKey.MatchString(k) {
+			out[k] = redacted
+			continue
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			out[k] = redactConfig(v)
+		case string:
+			if u, err := url.Parse(v); err == nil && u.User != nil {
+				u.User = url.User(redacted)
+				out[k] = u.String()
+			} else {
+				out[k] = v
+			}
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// reURLUserinfo matches the scheme and the userinfo of the urls in a text.
+var reURLUserinfo = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/?#\s@"']+@`)
+
+// redactURLs replaces the userinfo of the urls in s, e.g. the credentials of
+// a proxy.
+func redactURLs(s string) string {
+	return reURLUserinfo.ReplaceAllString(s, "${1}xxxxx@")
+}
+
+// redactText redacts the text of a log like redactConfig redacts the config:
+// the userinfo of the urls and the values of the credential keys of data are
+// replaced.
+func redactText(s string, data map[string]interface{}) string {
+	for k, v := range data {
+		if v, ok := v.(string); ok && len(v) >= 4 && reSecretKey.MatchString(k) {
+			s = strings.Replace(s, v, "xxxxx", -1)
+		}
+	}
+	return redactURLs(s)
+}
+
+// tailLines returns the last n lines of the file at p.
+func tailLines(p string, n int) ([]string, error) {
+	fd, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	var lines []string
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if n > 0 && len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// writeDiagnosticsReport creates a zip in CachePath() with a report of the
+// diagnostics sections, the logs, the redacted config and the Info.plist, and
+// returns its path.
+func writeDiagnosticsReport(c *Context) (string, error) {
+	a := c.Action
+	p := path.Join(a.CachePath(), fmt.Sprintf("diagnostics-%s.zip", time.Now().Format("20060102-150405")))
+	fd, err := os.Create(p)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	zw := zip.NewWriter(fd)
+
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "%s v%s\n%s\n", a.name, a.Version(), time.Now().Format(time.RFC3339))
+	for _, s := range diagnosticsSections(c) {
+		fmt.Fprintf(&report, "\n[%s]\n", s.Title)
+		for _, line := range s.Lines {
+			fmt.Fprintf(&report, "%s = %s\n", line[0], line[1])
+		}
+	}
+	w, err := zw.Create("report.txt")
+	if err != nil {
+		return "", err
+	}
+	w.Write(report.Bytes())
+
+	config, err := json.MarshalIndent(redactConfig(c.Config.data), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if w, err = zw.Create("config.json"); err != nil {
+		return "", err
+	}
+	w.Write(config)
+
+	if err := addFileToZip(zw, path.Join(a.ActionPath(), "Contents", "Info.plist"), nil); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	redact := func(s string) string { return redactText(s, c.Config.data) }
+	logs, _ := ioutil.ReadDir(a.SupportPath())
+	for _, f := range logs {
+		if !strings.HasPrefix(f.Name(), "error.log") {
+			continue
+		}
+		if err := addFileToZip(zw, path.Join(a.SupportPath(), f.Name()), redact); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// addFileToZip adds the file to the zip, passed through redact if it's not nil.
+func addFileToZip(zw *zip.Writer, name string, redact func(string) string) error {
+	rd, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	w, err := zw.Create(path.Base(name))
+	if err != nil {
+		return err
+	}
+	if redact == nil {
+		_, err = io.Copy(w, rd)
+		return err
+	}
+	data, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, redact(string(data)))
+	return err
+}
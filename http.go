@@ -0,0 +1,139 @@
+package launchbar
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPClient is a http client shared by the action and the update check.
+//
+// It sets a User-Agent that identifies the action, uses the timeout and proxy
+// from the config and retries the idempotent requests on network errors and
+// server errors.
+//
+// Config keys:
+//
+//	httpTimeout  request timeout in seconds (default 30)
+//	httpProxy    proxy url, if empty the proxy is taken from the environment
+//	httpRetries  number of retries (default 2)
+type HTTPClient struct {
+	Client    *http.Client
+	UserAgent string
+	Retries   int
+	Backoff   time.Duration // delay before the first retry, doubled on each retry
+}
+
+// NewHTTPClient initializes a new HTTPClient configured from the action's config.
+func NewHTTPClient(a *Action) *HTTPClient {
+	timeout := time.Duration(a.Config.GetFloat("httpTimeout") * float64(time.Second))
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if p := a.Config.GetString("httpProxy"); p != "" {
+		if u, err := url.Parse(p); err == nil {
+			proxy = http.ProxyURL(u)
+		} else if a.Logger != nil {
+			a.Logger.Printf("bad httpProxy %q: %v", redactURLs(p), redactURLs(err.Error()))
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	return &HTTPClient{
+		Client:    &http.Client{Timeout: timeout, Transport: transport},
+		UserAgent: userAgent(a),
+		Retries:   int(a.Config.GetInt("httpRetries")),
+		Backoff:   500 * time.Millisecond,
+	}
+}
+
+func userAgent(a *Action) string {
+	name := strings.Replace(a.name, " ", "-", -1)
+	if v := a.info.CFBundleVersion; v != "" {
+		return fmt.Sprintf("%s/%s go-launchbar", name, v)
+	}
+	return fmt.Sprintf("%s go-launchbar", name)
+}
+
+// Do sends the request and returns the response. GET and HEAD requests are
+// retried with backoff when the request fails or the server responds with 429
+// or 5xx.
+func (h *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" && h.UserAgent != "" {
+		req.Header.Set("User-Agent", h.UserAgent)
+	}
+
+	retries := h.Retries
+	if req.Method != "GET" && req.Method != "HEAD" {
+		retries = 0
+	}
+
+	backoff := h.Backoff
+	for n := 0; ; n++ {
+		resp, err := h.Client.Do(req)
+		if n >= retries || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// Get issues a GET to the specified URL.
+func (h *HTTPClient) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.Do(req)
+}
+
+// Head issues a HEAD to the specified URL.
+func (h *HTTPClient) Head(url string) (*http.Response, error) {
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.Do(req)
+}
+
+// GetIfModified issues a conditional GET to the specified URL. etag and
+// lastModified are the values of the ETag and Last-Modified headers of the
+// previous response, either may be empty.
+//
+// If the resource has not been changed the response status is 304 (Not Modified)
+// and the body is empty.
+func (h *HTTPClient) GetIfModified(url, etag, lastModified string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return h.Do(req)
+}
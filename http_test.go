@@ -0,0 +1,52 @@
+package launchbar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientRetry(t *testing.T) {
+	n := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		if r.Header.Get("User-Agent") != "test/1.0" {
+			t.Errorf("bad user agent: %q", r.Header.Get("User-Agent"))
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	h := &HTTPClient{Client: ts.Client(), UserAgent: "test/1.0", Retries: 2, Backoff: time.Millisecond}
+	resp, err := h.GetIfModified(ts.URL, `"abc"`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected 304 got %d", resp.StatusCode)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 requests got %d", n)
+	}
+
+	n = 0
+	h.Retries = 1
+	resp, err = h.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after giving up got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,536 @@
+package launchbar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+// builtinStrings are the strings of the items the package adds to an action.
+// An action can translate or override them in its own catalogs.
+var builtinStrings = map[string]catalog{
+	"en": {
+		"update.available": {text: "New Version Available: v%s (I'm v%s)"},
+		"update.download":  {text: "Download %s"},
+		"update.homepage":  {text: "Open Homepage"},
+		"nav.back":         {text: "← Back"},
+		"render.loading":   {text: "loading…"},
+		"stream.searching": {plural: map[string]string{"one": "Searching… (%d result)", "other": "Searching… (%d results)"}},
+		"error":            {text: "Error"},
+
+		"diagnostics.report":          {text: "Create Diagnostics Report"},
+		"diagnostics.report.subtitle": {text: "Creates a zip file to attach to bug reports"},
+		"diagnostics.report.created":  {text: "Diagnostics report"},
+		"diagnostics.report.failed":   {text: "Cannot create the diagnostics report"},
+		"diagnostics.log":             {text: "Log"},
+		"diagnostics.log.lines":       {plural: map[string]string{"one": "last %d line", "other": "last %d lines"}},
+		"diagnostics.environment":     {text: "Environment"},
+		"diagnostics.config":          {text: "Config"},
+		"diagnostics.cache":           {text: "Cache"},
+		"diagnostics.update":          {text: "Update"},
+		"diagnostics.entries":         {plural: map[string]string{"one": "%d entry", "other": "%d entries"}},
+
+		"time.justNow": {text: "just now"},
+		"time.ago":     {text: "%s ago"},
+		"time.in":      {text: "in %s"},
+		"time.minutes": {plural: map[string]string{"one": "%d minute", "other": "%d minutes"}},
+		"time.hours":   {plural: map[string]string{"one": "%d hour", "other": "%d hours"}},
+		"time.days":    {plural: map[string]string{"one": "%d day", "other": "%d days"}},
+		"time.months":  {plural: map[string]string{"one": "%d month", "other": "%d months"}},
+		"time.years":   {plural: map[string]string{"one": "%d year", "other": "%d years"}},
+	},
+}
+
+// englishStrings is the localizer of the TemplateFuncs used without an action.
+var englishStrings = &localizer{langs: []string{"en"}, catalogs: []catalog{builtinStrings["en"]}}
+
+// message is a translated string, plural messages have a form per plural
+// category (zero, one, two, few, many, other), see pluralCategory.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+func (m *message) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(b, &m.plural)
+}
+
+// catalog maps the keys to the messages of a language.
+type catalog map[string]message
+
+// localizer looks up the messages in the catalogs of the fallback chain of
+// the locale.
+type localizer struct {
+	langs    []string
+	catalogs []catalog
+}
+
+// Locale returns the locale of the action, e.g. "de-CH". It's read from the
+// config key locale, LC_ALL, LC_MESSAGES or LANG, in this order. LaunchBar
+// runs the actions without these variables, the first of the preferred
+// languages of macOS is used then, they're cached for a day. It defaults to
+// "en".
+func (a *Action) Locale() string {
+	for _, s := range []string{a.Config.GetString("locale"), os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if l := normalizeLocale(s); l != "" {
+			return l
+		}
+	}
+	for _, s := range a.preferredLanguages() {
+		if l := normalizeLocale(s); l != "" {
+			return l
+		}
+	}
+	return "en"
+}
+
+// preferredLanguages returns the preferred languages of macOS from the cache
+// of the action, reading them costs a defaults run.
+func (a *Action) preferredLanguages() []string {
+	var langs []string
+	if _, err := a.Cache.Get(appleLanguagesKey, &langs); err == nil {
+		return langs
+	}
+	langs = preferredLanguages()
+	if len(langs) > 0 {
+		a.Cache.Set(appleLanguagesKey, langs, 24*time.Hour)
+	}
+	return langs
+}
+
+const appleLanguagesKey = "AppleLanguages"
+
+// preferredLanguages returns the preferred languages of the macOS user, the
+// AppleLanguages preference. It's a variable to be replaced in the tests.
+var preferredLanguages = func() []string {
+	appleLanguagesOnce.Do(func() {
+		if runtime.GOOS != "darwin" {
+			return
+		}
+		out, err := exec.Command("defaults", "read", "-g", "AppleLanguages").Output()
+		if err == nil {
+			appleLanguages = parseAppleLanguages(out)
+		}
+	})
+	return appleLanguages
+}
+
+var (
+	appleLanguages     []string
+	appleLanguagesOnce sync.Once
+)
+
+// parseAppleLanguages parses the array printed by defaults, e.g.
+//
+//	(
+//	    "de-CH",
+//	    en
+//	)
+func parseAppleLanguages(out []byte) []string {
+	var langs []string
+	s := strings.Trim(strings.TrimSpace(string(out)), "()")
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.Trim(strings.TrimSpace(l), `"`); l != "" {
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
+
+// normalizeLocale turns a POSIX locale, e.g. pt_BR.UTF-8, into a language
+// tag, e.g. pt-BR. It returns "" for the C and POSIX locales.
+func normalizeLocale(s string) string {
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" || s == "C" || s == "POSIX" {
+		return ""
+	}
+	return strings.Replace(s, "_", "-", -1)
+}
+
+// localeChain returns the languages that are tried in order for the locale,
+// e.g. zh-Hant-TW: zh-Hant-TW, zh-Hant, zh, en, Base.
+func localeChain(locale string) []string {
+	var chain []string
+	for l := locale; l != ""; {
+		chain = append(chain, l)
+		i := strings.LastIndex(l, "-")
+		if i < 0 {
+			break
+		}
+		l = l[:i]
+	}
+	for _, l := range []string{"en", "Base"} {
+		if !containsFold(chain, l) {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// localizer returns the localizer of the action, the catalogs are loaded
+// on the first use. The locale is only looked up if the bundle has catalogs,
+// the built in strings are English.
+func (a *Action) localizer() *localizer {
+	a.i18nOnce.Do(func() {
+		locale := "en"
+		if lprojs, _ := filepath.Glob(path.Join(a.ActionPath(), "Contents", "Resources", "*.lproj")); len(lprojs) > 0 {
+			locale = a.Locale()
+		}
+		a.i18n = a.loadLocalizer(locale)
+	})
+	return a.i18n
+}
+
+// loadLocalizer loads the .strings and .json catalogs of the <lang>.lproj
+// directories in Contents/Resources of the action bundle for the locale.
+func (a *Action) loadLocalizer(locale string) *localizer {
+	res := path.Join(a.ActionPath(), "Contents", "Resources")
+	dirs, _ := ioutil.ReadDir(res)
+
+	l := &localizer{}
+	for _, lang := range localeChain(locale) {
+		for _, d := range dirs {
+			name := strings.TrimSuffix(d.Name(), ".lproj")
+			if !d.IsDir() || name == d.Name() || !strings.EqualFold(strings.Replace(name, "_", "-", -1), lang) {
+				continue
+			}
+			cat, err := loadCatalog(path.Join(res, d.Name()))
+			if err != nil {
+				a.Log.Warn("cannot load the strings", "lang", lang, "error", err)
+			}
+			l.add(lang, cat)
+		}
+	}
+	for _, lang := range localeChain(locale) {
+		if cat, ok := builtinStrings[lang]; ok {
+			l.add(lang, cat)
+		}
+	}
+	return l
+}
+
+func (l *localizer) add(lang string, cat catalog) {
+	if len(cat) > 0 {
+		l.langs = append(l.langs, lang)
+		l.catalogs = append(l.catalogs, cat)
+	}
+}
+
+// loadCatalog reads the .strings and .json files of dir, the files are read in
+// lexical order and later keys override the earlier ones.
+func loadCatalog(dir string) (catalog, error) {
+	files, err := filepath.Glob(path.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	cat := catalog{}
+	for _, f := range files {
+		ext := path.Ext(f)
+		if ext != ".strings" && ext != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return cat, err
+		}
+		var c catalog
+		if ext == ".json" {
+			err = json.Unmarshal(data, &c)
+		} else {
+			c, err = parseStrings(data)
+		}
+		if err != nil {
+			return cat, fmt.Errorf("%s: %v", path.Base(f), err)
+		}
+		for k, m := range c {
+			cat[k] = m
+		}
+	}
+	return cat, nil
+}
+
+// parseStrings parses an Apple .strings file in UTF-8 or UTF-16:
+//
+//	/* comment */
+//	"key" = "value";
+//
+// A key with a plural category suffix, e.g. "results.one" and
+// "results.other", defines the forms of the plural message "results".
+func parseStrings(data []byte) (catalog, error) {
+	s := decodeUTF16(data)
+	cat := catalog{}
+	pos := 0
+	skip := func() {
+		for pos < len(s) {
+			switch {
+			case strings.ContainsRune(" \t\r\n", rune(s[pos])):
+				pos++
+			case strings.HasPrefix(s[pos:], "//"):
+				if i := strings.IndexByte(s[pos:], '\n'); i >= 0 {
+					pos += i
+				} else {
+					pos = len(s)
+				}
+			case strings.HasPrefix(s[pos:], "/*"):
+				if i := strings.Index(s[pos+2:], "*/"); i >= 0 {
+					pos += i + 4
+				} else {
+					pos = len(s)
+				}
+			default:
+				return
+			}
+		}
+	}
+	expect := func(c byte) error {
+		skip()
+		if pos >= len(s) || s[pos] != c {
+			return fmt.Errorf("offset %d: expected %q", pos, c)
+		}
+		pos++
+		return nil
+	}
+	str := func() (string, error) {
+		if err := expect('"'); err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		for ; pos < len(s); pos++ {
+			switch s[pos] {
+			case '"':
+				pos++
+				return b.String(), nil
+			case '\\':
+				pos++
+				if pos >= len(s) {
+					break
+				}
+				switch s[pos] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[pos])
+				}
+			default:
+				b.WriteByte(s[pos])
+			}
+		}
+		return "", fmt.Errorf("unterminated string")
+	}
+
+	for skip(); pos < len(s); skip() {
+		key, err := str()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect('='); err != nil {
+			return nil, err
+		}
+		val, err := str()
+		if err != nil {
+			return nil, err
+		}
+		if err := expect(';'); err != nil {
+			return nil, err
+		}
+		if i := strings.LastIndex(key, "."); i >= 0 && isPluralCategory(key[i+1:]) {
+			m := cat[key[:i]]
+			if m.plural == nil {
+				m.plural = map[string]string{}
+			}
+			m.plural[key[i+1:]] = val
+			cat[key[:i]] = m
+			continue
+		}
+		cat[key] = message{text: val}
+	}
+	return cat, nil
+}
+
+// decodeUTF16 returns the data as string, decoding UTF-16 if it starts with a
+// byte order mark.
+func decodeUTF16(data []byte) string {
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[1]) | uint16(b[0])<<8 }
+	default:
+		return string(bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf}))
+	}
+	u := make([]uint16, 0, len(data)/2)
+	for i := 2; i+1 < len(data); i += 2 {
+		u = append(u, order(data[i:i+2]))
+	}
+	return string(utf16.Decode(u))
+}
+
+func isPluralCategory(s string) bool {
+	switch s {
+	case "zero", "one", "two", "few", "many", "other":
+		return true
+	}
+	return false
+}
+
+// pluralCategory returns the CLDR plural category of the integer n in the
+// language, for the common languages of LaunchBar users.
+func pluralCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	if i := strings.Index(lang, "-"); i >= 0 && !strings.EqualFold(lang, "pt-PT") {
+		lang = lang[:i]
+	}
+	switch strings.ToLower(lang) {
+	case "ja", "ko", "zh", "th", "vi", "id", "ms":
+		return "other"
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs", "sk":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// T returns the message of the key in the locale of the action (see Locale)
+// formatted with the args like fmt.Sprintf. The message is looked up in the
+// catalogs of the locale, its parent languages, en and Base, in this order;
+// if none has the key, the key itself is returned, formatted if it has verbs.
+//
+// A plural message picks its form with the first int argument:
+//
+//	// Resources/de.lproj/Localizable.strings:
+//	//	"results.one" = "%d Ergebnis";
+//	//	"results.other" = "%d Ergebnisse";
+//	c.T("results", n)
+func (a *Action) T(key string, args ...interface{}) string {
+	return a.localizer().t(key, args...)
+}
+
+// t returns the formatted message of the key, see Action.T.
+func (l *localizer) t(key string, args ...interface{}) string {
+	if m, lang, ok := l.lookup(key); ok {
+		return m.format(lang, args)
+	}
+	if !strings.Contains(key, "%") {
+		return key
+	}
+	return sprintf(key, args)
+}
+
+// lookup returns the first message of the key in the catalogs and the
+// language of its catalog.
+func (l *localizer) lookup(key string) (message, string, bool) {
+	for n, cat := range l.catalogs {
+		if m, ok := cat[key]; ok {
+			return m, l.langs[n], true
+		}
+	}
+	return message{}, "", false
+}
+
+// format formats the message with the args, a plural message picks the form
+// for the language.
+func (m message) format(lang string, args []interface{}) string {
+	text := m.text
+	if m.plural != nil {
+		text = m.plural[pluralCategory(lang, pluralCount(args))]
+		if text == "" {
+			text = m.plural["other"]
+		}
+	}
+	return sprintf(text, args)
+}
+
+// T returns the localized message of the key, see Action.T.
+func (c *Context) T(key string, args ...interface{}) string { return c.Action.T(key, args...) }
+
+// pluralCount returns the first int argument.
+func pluralCount(args []interface{}) int {
+	for _, arg := range args {
+		switch arg.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			return toInt(arg)
+		}
+	}
+	return 0
+}
+
+func sprintf(format string, args []interface{}) string {
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,153 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseStrings(t *testing.T) {
+	src := `/* Localizable.strings */
+"hello" = "Hallo \"%s\"";  // greeting
+"results.one" = "%d Ergebnis";
+"results.other" = "%d Ergebnisse";
+"multi\nline" = "a\tb";
+`
+	want := catalog{
+		"hello":       {text: `Hallo "%s"`},
+		"results":     {plural: map[string]string{"one": "%d Ergebnis", "other": "%d Ergebnisse"}},
+		"multi\nline": {text: "a\tb"},
+	}
+	cat, err := parseStrings([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cat, want) {
+		t.Errorf("got %v want %v", cat, want)
+	}
+
+	u := utf16.Encode([]rune(src))
+	b := []byte{0xff, 0xfe}
+	for _, c := range u {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	if cat, err := parseStrings(b); err != nil || !reflect.DeepEqual(cat, want) {
+		t.Errorf("utf-16: got %v, %v", cat, err)
+	}
+
+	for _, bad := range []string{`"a" = "b"`, `"a" "b";`, `"a = "b";`, `a = "b";`} {
+		if _, err := parseStrings([]byte(bad)); err == nil {
+			t.Errorf("parseStrings(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	tests := map[string][]string{
+		"zh-Hant-TW": {"zh-Hant-TW", "zh-Hant", "zh", "en", "Base"},
+		"de":         {"de", "en", "Base"},
+		"en-GB":      {"en-GB", "en", "Base"},
+	}
+	for locale, want := range tests {
+		if got := localeChain(locale); !reflect.DeepEqual(got, want) {
+			t.Errorf("localeChain(%q) = %v want %v", locale, got, want)
+		}
+	}
+	for s, want := range map[string]string{"pt_BR.UTF-8": "pt-BR", "C": "", "de_DE@euro": "de-DE", "": ""} {
+		if got := normalizeLocale(s); got != want {
+			t.Errorf("normalizeLocale(%q) = %q want %q", s, got, want)
+		}
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "one"}, {"en", 0, "other"}, {"en", 2, "other"},
+		{"fr", 0, "one"}, {"pt-BR", 0, "one"}, {"pt-PT", 0, "other"},
+		{"ja", 1, "other"},
+		{"ru", 1, "one"}, {"ru", 3, "few"}, {"ru", 5, "many"}, {"ru", 11, "many"}, {"ru", 21, "one"},
+		{"pl", 22, "few"}, {"pl", 12, "many"},
+		{"ar", 2, "two"}, {"ar", 100, "other"},
+	}
+	for _, test := range tests {
+		if got := pluralCategory(test.lang, test.n); got != test.want {
+			t.Errorf("pluralCategory(%q, %d) = %q want %q", test.lang, test.n, got, test.want)
+		}
+	}
+}
+
+func TestActionT(t *testing.T) {
+	t.Setenv("LC_ALL", "de_CH.UTF-8")
+	a := newTestAction(t, nil)
+	res := path.Join(a.ActionPath(), "Contents", "Resources")
+	for file, data := range map[string]string{
+		"de.lproj/Localizable.strings": `"hello" = "Hallo %s"; "update.homepage" = "Webseite öffnen";`,
+		"de.lproj/More.json":           `{"results": {"one": "%d Ergebnis", "other": "%d Ergebnisse"}}`,
+		"de_CH.lproj/Localizable.json": `{"hello": "Grüezi %s"}`,
+		"en.lproj/Localizable.strings": `"bye" = "Bye";`,
+	} {
+		os.MkdirAll(path.Join(res, path.Dir(file)), 0755)
+		if err := ioutil.WriteFile(path.Join(res, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if l := a.Locale(); l != "de-CH" {
+		t.Errorf("Locale() = %q", l)
+	}
+	tests := []struct {
+		key  string
+		args []interface{}
+		want string
+	}{
+		{"hello", []interface{}{"Sam"}, "Grüezi Sam"},
+		{"results", []interface{}{1}, "1 Ergebnis"},
+		{"results", []interface{}{3}, "3 Ergebnisse"},
+		{"update.homepage", nil, "Webseite öffnen"},
+		{"bye", nil, "Bye"},
+		{"nav.back", nil, "← Back"},
+		{"stream.searching", []interface{}{2}, "Searching… (2 results)"},
+		{"Missing %d", []interface{}{7}, "Missing 7"},
+		{"Missing", []interface{}{7}, "Missing"},
+	}
+	for _, test := range tests {
+		if got := a.context.T(test.key, test.args...); got != test.want {
+			t.Errorf("T(%q, %v) = %q want %q", test.key, test.args, got, test.want)
+		}
+	}
+}
+
+func TestLocaleAppleLanguages(t *testing.T) {
+	langs := parseAppleLanguages([]byte("(\n    \"de-CH\",\n    en\n)\n"))
+	if !reflect.DeepEqual(langs, []string{"de-CH", "en"}) {
+		t.Errorf("parseAppleLanguages = %q", langs)
+	}
+
+	defer func(f func() []string) { preferredLanguages = f }(preferredLanguages)
+	calls := 0
+	preferredLanguages = func() []string { calls++; return langs }
+	a := newTestAction(t, nil)
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(k, "")
+	}
+	if a.T("nav.back"); calls != 0 {
+		t.Errorf("the preferred languages are read for a bundle without catalogs")
+	}
+	if l := a.Locale(); l != "de-CH" {
+		t.Errorf("Locale() = %q, want the preferred language", l)
+	}
+	if l := a.Locale(); l != "de-CH" || calls != 1 {
+		t.Errorf("Locale() = %q, the preferred languages are read %d times, want them cached", l, calls)
+	}
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	if l := a.Locale(); l != "fr-FR" || calls != 1 {
+		t.Errorf("Locale() = %q, want LANG without reading the preferred languages", l)
+	}
+}
@@ -0,0 +1,209 @@
+package launchbar
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/DHowett/go-plist"
+)
+
+// InfoPlist is the Info.plist of an action bundle, see
+// https://developer.obdev.at/launchbar-developer-documentation/#/action-info-plist
+type InfoPlist struct {
+	CFBundleIdentifier         string        `plist:"CFBundleIdentifier"`
+	CFBundleName               string        `plist:"CFBundleName"`
+	CFBundleVersion            string        `plist:"CFBundleVersion"`
+	CFBundleShortVersionString string        `plist:"CFBundleShortVersionString,omitempty"`
+	CFBundleIconFile           string        `plist:"CFBundleIconFile,omitempty"`
+	LSMinimumSystemVersion     string        `plist:"LSMinimumSystemVersion,omitempty"`
+	LBMinimumLaunchBarVersion  string        `plist:"LBMinimumLaunchBarVersion,omitempty"`
+	LBAbbreviation             string        `plist:"LBAbbreviation,omitempty"`
+	LBAssociatedApplication    string        `plist:"LBAssociatedApplication,omitempty"`
+	LBRequiredApplication      StringList    `plist:"LBRequiredApplication,omitempty"`
+	LBDebugLogEnabled          bool          `plist:"LBDebugLogEnabled,omitempty"`
+	LBTextInputTitle           string        `plist:"LBTextInputTitle,omitempty"`
+	LBScripts                  LBScripts     `plist:"LBScripts"`
+	LBDescription              LBDescription `plist:"LBDescription"`
+
+	// Raw has all the keys of the file, including the ones not modeled here.
+	Raw map[string]interface{} `plist:"-"`
+}
+
+// LBScripts are the scripts of the action.
+type LBScripts struct {
+	LBDefaultScript     *LBScript `plist:"LBDefaultScript,omitempty"`
+	LBSuggestionsScript *LBScript `plist:"LBSuggestionsScript,omitempty"`
+	LBActionURLScript   *LBScript `plist:"LBActionURLScript,omitempty"`
+}
+
+// LBScript describes a script of the action.
+type LBScript struct {
+	LBScriptName              string     `plist:"LBScriptName"`
+	LBRunInBackground         bool       `plist:"LBRunInBackground,omitempty"`
+	LBRequiresArgument        bool       `plist:"LBRequiresArgument,omitempty"`
+	LBAcceptedArgumentTypes   StringList `plist:"LBAcceptedArgumentTypes,omitempty"`
+	LBReturnsResult           bool       `plist:"LBReturnsResult,omitempty"`
+	LBResultType              string     `plist:"LBResultType,omitempty"`
+	LBLiveFeedbackEnabled     bool       `plist:"LBLiveFeedbackEnabled,omitempty"`
+	LBBackgroundKillEnabled   bool       `plist:"LBBackgroundKillEnabled,omitempty"`
+	LBAllowsMultipleInstances bool       `plist:"LBAllowsMultipleInstances,omitempty"`
+	LBKeepWindowActive        bool       `plist:"LBKeepWindowActive,omitempty"`
+}
+
+// LBDescription is the description of the action shown by LaunchBar. LBUpdate
+// and LBDownload are used by the auto update (see Action.Run).
+type LBDescription struct {
+	LBAuthor       string `plist:"LBAuthor,omitempty"`
+	LBEmail        string `plist:"LBEmail,omitempty"`
+	LBWebsite      string `plist:"LBWebsite,omitempty"`
+	LBTwitter      string `plist:"LBTwitter,omitempty"`
+	LBSummary      string `plist:"LBSummary,omitempty"`
+	LBArgument     string `plist:"LBArgument,omitempty"`
+	LBResult       string `plist:"LBResult,omitempty"`
+	LBRequirements string `plist:"LBRequirements,omitempty"`
+	LBChangelog    string `plist:"LBChangelog,omitempty"`
+	LBUpdate       string `plist:"LBUpdate,omitempty"`
+	LBDownload     string `plist:"LBDownload,omitempty"`
+}
+
+// StringList is a plist value that is either a string or an array of strings.
+type StringList []string
+
+// UnmarshalPlist accepts a string or an array of strings.
+func (l *StringList) UnmarshalPlist(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*l = StringList{s}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// ParseInfoPlist parses the Info.plist data in any plist format. It returns
+// an error if the data is not a plist, use Validate to check the keys.
+func ParseInfoPlist(data []byte) (*InfoPlist, error) {
+	info := &InfoPlist{}
+	if _, err := plist.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("Info.plist: %v", err)
+	}
+	if _, err := plist.Unmarshal(data, &info.Raw); err != nil {
+		return nil, fmt.Errorf("Info.plist: %v", err)
+	}
+	return info, nil
+}
+
+// ReadInfoPlist reads and parses the Info.plist file.
+func ReadInfoPlist(p string) (*InfoPlist, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfoPlist(data)
+}
+
+// InfoPlistError lists the problems found by InfoPlist.Validate.
+type InfoPlistError struct {
+	Problems []string
+}
+
+func (e *InfoPlistError) Error() string {
+	return "Info.plist: " + strings.Join(e.Problems, "; ")
+}
+
+var reBundleIdentifier = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+
+// Validate checks the required keys and the format of the values LaunchBar
+// and this package depend on. It returns an *InfoPlistError or nil.
+func (info *InfoPlist) Validate() error {
+	problems := info.validate()
+	if len(problems) == 0 {
+		return nil
+	}
+	e := &InfoPlistError{}
+	for _, p := range problems {
+		e.Problems = append(e.Problems, p.key+" "+p.msg)
+	}
+	return e
+}
+
+// infoProblem is a problem of the value of key found by validate.
+type infoProblem struct {
+	key, msg string
+}
+
+func (info *InfoPlist) validate() []infoProblem {
+	var problems []infoProblem
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, infoProblem{key, fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case info.CFBundleIdentifier == "":
+		add("CFBundleIdentifier", "is missing")
+	case !reBundleIdentifier.MatchString(info.CFBundleIdentifier):
+		add("CFBundleIdentifier", "%q is not a reverse DNS identifier, e.g. com.example.action", info.CFBundleIdentifier)
+	}
+	if info.CFBundleName == "" {
+		add("CFBundleName", "is missing")
+	}
+	switch {
+	case info.CFBundleVersion == "":
+		add("CFBundleVersion", "is missing")
+	case !Version(info.CFBundleVersion).Valid():
+		add("CFBundleVersion", "%q is not a version, e.g. 1.2.3", info.CFBundleVersion)
+	}
+	if v := info.LBMinimumLaunchBarVersion; v != "" && !Version(v).Valid() {
+		add("LBMinimumLaunchBarVersion", "%q is not a version, e.g. 6.0", v)
+	}
+
+	if info.LBScripts.LBDefaultScript == nil {
+		add("LBScripts.LBDefaultScript", "is missing")
+	}
+	for _, s := range info.LBScripts.list() {
+		if s.script.LBScriptName == "" {
+			add("LBScripts."+s.name+".LBScriptName", "is missing")
+		}
+	}
+
+	d := info.LBDescription
+	for _, u := range [][2]string{{"LBWebsite", d.LBWebsite}, {"LBUpdate", d.LBUpdate}, {"LBDownload", d.LBDownload}} {
+		if u[1] == "" {
+			continue
+		}
+		if p, err := url.Parse(u[1]); err != nil || (p.Scheme != "http" && p.Scheme != "https") || p.Host == "" {
+			add("LBDescription."+u[0], "%q is not a http(s) url", u[1])
+		}
+	}
+	if d.LBEmail != "" && !reEmail.MatchString(d.LBEmail) {
+		add("LBDescription.LBEmail", "%q is not an email address", d.LBEmail)
+	}
+	return problems
+}
+
+type namedScript struct {
+	name   string
+	script *LBScript
+}
+
+// list returns the scripts that are set.
+func (s LBScripts) list() []namedScript {
+	var list []namedScript
+	for _, ns := range []namedScript{
+		{"LBDefaultScript", s.LBDefaultScript},
+		{"LBSuggestionsScript", s.LBSuggestionsScript},
+		{"LBActionURLScript", s.LBActionURLScript},
+	} {
+		if ns.script != nil {
+			list = append(list, ns)
+		}
+	}
+	return list
+}
@@ -0,0 +1,88 @@
+package launchbar
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInfoPlist(t *testing.T) {
+	info, err := ParseInfoPlist([]byte(testInfoPlist))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if info.CFBundleIdentifier != "com.example.test" || info.LBDescription.LBAuthor != "Test" || info.LBScripts.LBDefaultScript.LBScriptName != "default.sh" {
+		t.Errorf("bad info: %+v", info)
+	}
+	if info.Raw["CFBundleName"] != "Test" {
+		t.Errorf("Raw = %v", info.Raw)
+	}
+
+	for _, test := range []struct {
+		value string
+		want  StringList
+	}{
+		{`<string>com.apple.Safari</string>`, StringList{"com.apple.Safari"}},
+		{`<array><string>com.apple.Safari</string><string>com.google.Chrome</string></array>`, StringList{"com.apple.Safari", "com.google.Chrome"}},
+	} {
+		data := strings.Replace(testInfoPlist, "<dict>\n\t<key>CFBundleIdentifier", "<dict>\n\t<key>LBRequiredApplication</key>"+test.value+"\n\t<key>CFBundleIdentifier", 1)
+		info, err := ParseInfoPlist([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(info.LBRequiredApplication, test.want) {
+			t.Errorf("LBRequiredApplication = %v want %v", info.LBRequiredApplication, test.want)
+		}
+	}
+
+	if _, err := ParseInfoPlist([]byte("not a plist")); err == nil {
+		t.Errorf("ParseInfoPlist did not fail")
+	}
+}
+
+func TestInfoPlistValidate(t *testing.T) {
+	info := &InfoPlist{
+		CFBundleIdentifier: "test",
+		CFBundleVersion:    "1.2.3.4",
+		LBScripts:          LBScripts{LBSuggestionsScript: &LBScript{}},
+		LBDescription:      LBDescription{LBUpdate: "ftp://example.com/update.plist", LBEmail: "nobody"},
+	}
+	err, ok := info.Validate().(*InfoPlistError)
+	if !ok {
+		t.Fatalf("Validate() = %v", err)
+	}
+	want := []string{
+		`CFBundleIdentifier "test" is not a reverse DNS identifier`,
+		`CFBundleName is missing`,
+		`CFBundleVersion "1.2.3.4" is not a version`,
+		`LBScripts.LBDefaultScript is missing`,
+		`LBScripts.LBSuggestionsScript.LBScriptName is missing`,
+		`LBDescription.LBUpdate "ftp://example.com/update.plist" is not a http(s) url`,
+		`LBDescription.LBEmail "nobody" is not an email address`,
+	}
+	if len(err.Problems) != len(want) {
+		t.Fatalf("got problems:\n%s", strings.Join(err.Problems, "\n"))
+	}
+	for i, p := range err.Problems {
+		if !strings.HasPrefix(p, want[i]) {
+			t.Errorf("problem %d = %q want %q", i, p, want[i])
+		}
+	}
+}
+
+func TestActionWithoutInfoPlist(t *testing.T) {
+	a := newTestAction(t, nil)
+	os.Remove(path.Join(a.ActionPath(), "Contents", "Info.plist"))
+	a = NewAction("Test", ConfigValues{"actionDefaultScript": "default.sh", "autoUpdate": false})
+	if v := a.Version(); v != "" {
+		t.Errorf("Version() = %q", v)
+	}
+	if out := a.Run(); !strings.Contains(out, "Info.plist") {
+		t.Errorf("Run() = %s", out)
+	}
+}
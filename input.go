@@ -3,9 +3,11 @@ package launchbar
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
-	"strconv"
+	"path"
 	"strings"
+	"time"
 )
 
 // Input represents the object that LaunchBar passes to scripts
@@ -13,6 +15,9 @@ type Input struct {
 	Item *Item
 
 	args           []string
+	kind           InputKind
+	kinds          []InputKind
+	unaccepted     bool
 	isObject       bool
 	isString       bool
 	isPaths        bool
@@ -21,9 +26,11 @@ type Input struct {
 	isInt          bool
 	isLiveFeedback bool
 	hasFunc        bool
+	funcRejected   bool
 	hasData        bool
 	paths          []string
 	number         float64
+	text           *classified
 }
 
 func exists(p string) bool {
@@ -31,30 +38,33 @@ func exists(p string) bool {
 	return err == nil
 }
 
+// NewInput parses the arguments passed to the action.
+//
+// The input is classified into the kinds it can be interpreted as (see
+// ClassifyInput) and Kind() is resolved to the first of them accepted by the
+// action (see Action.Accept). A single argument is only taken as a path if
+// it's absolute and exists, and only as an item if it's a json object.
 func NewInput(a *Action, args []string) *Input {
 	item := item{}
 	var in = &Input{
 		args: args,
+		kind: KindEmpty,
 	}
 
 	if len(args) == 0 {
+		in.kinds = []InputKind{KindEmpty}
 		return in
 	}
 
 	in.isLiveFeedback = a.IsBackground()
 	if len(args) > 1 {
-		in.isPaths = true
-		in.paths = args
+		in.kinds = []InputKind{KindPaths}
+		in.resolve(a)
 		return in
 	}
 
-	if len(args) == 1 && exists(args[0]) {
-		in.isPaths = true
-		in.paths = args
-		return in
-	}
-
-	if err := json.Unmarshal([]byte(args[0]), &item); err == nil {
+	if strings.HasPrefix(strings.TrimSpace(args[0]), "{") && json.Unmarshal([]byte(args[0]), &item) == nil {
+		in.kinds = []InputKind{KindObject}
 		in.isObject = true
 		if item.Data != nil && len(item.Data) > 0 {
 			in.hasData = true
@@ -70,15 +80,44 @@ func NewInput(a *Action, args []string) *Input {
 		in.Item.item.FuncArg = item.FuncArg
 		in.Item.item.Data = item.Data
 		if item.FuncName != "" {
-			in.hasFunc = true
+			if verifyItem(a.secret, &item) || a.public[item.FuncName] {
+				in.hasFunc = true
+			} else {
+				in.funcRejected = true
+			}
 		}
 		if in.Item.item.Path != "" && exists(in.Item.item.Path) {
 			in.isPaths = true
 			in.paths = []string{in.Item.item.Path}
 		}
+		in.kind = KindObject
+		return in
+	}
+
+	in.text = classify(args[0], time.Now())
+	if path.IsAbs(args[0]) && exists(args[0]) {
+		in.kinds = append([]InputKind{KindPaths}, in.text.kinds...)
 	} else {
+		in.kinds = in.text.kinds
+	}
+	in.resolve(a)
+	return in
+
+}
+
+// resolve sets the kind of a non object input and the legacy flags.
+func (in *Input) resolve(a *Action) {
+	kind, ok := resolveKind(in.kinds, a.accept)
+	in.kind, in.unaccepted = kind, !ok
+	switch in.kind {
+	case KindPaths:
+		in.isPaths = true
+		in.paths = in.args
+	case KindEmpty:
+	default:
 		in.isString = true
-		if f64, err := strconv.ParseFloat(in.String(), 64); err == nil {
+		if in.text != nil && in.Is(KindNumber) {
+			f64 := in.text.number
 			in.isNumber = true
 			in.number = f64
 			if fmt.Sprintf("%f", f64) == fmt.Sprintf("%f", float64(int64(f64))) {
@@ -88,8 +127,6 @@ func NewInput(a *Action, args []string) *Input {
 			}
 		}
 	}
-	return in
-
 }
 
 func (in *Input) Int() int         { return int(in.number) }
@@ -104,6 +141,7 @@ func (in *Input) String() string {
 	return in.Raw()
 }
 
+// FuncArg returns the first argument of the func (see Item.Run) as a string.
 func (in *Input) FuncArg() string {
 	if out := in.FuncArgs(); len(out) > 0 {
 		return out[0]
@@ -111,40 +149,35 @@ func (in *Input) FuncArg() string {
 	return ""
 }
 
-// TODO:
-// Deprecated use FuncArgs
-func (in *Input) FuncArgsString() []string {
-	out := in.FuncArgs()
-	l := len(out)
-	if l == 0 {
+// FuncArgs returns the arguments of the func (see Item.Run) as strings.
+// Strings are returned as is, other values in their json encoding.
+func (in *Input) FuncArgs() map[int]string {
+	if !in.isObject {
 		return nil
 	}
-	args := make([]string, l)
-	for i := 0; i < l; i++ {
-		args[i] = out[i]
+	out := make(map[int]string)
+	for i, arg := range decodeFuncArgs(in.Item.item.FuncArg) {
+		var s string
+		if err := json.Unmarshal(arg, &s); err == nil {
+			out[i] = s
+		} else {
+			out[i] = string(arg)
+		}
 	}
-	return args
+	return out
 }
 
-// TODO:
-// Deprecated use FuncArgs
-func (in *Input) FuncArgsMapString() map[int]string { return in.FuncArgs() }
-
-func (in *Input) FuncArgs() map[int]string {
-	out := make(map[int]string)
+// DecodeFuncArg decodes the nth argument of the func (see Item.Run) into the
+// value pointed to by v.
+func (in *Input) DecodeFuncArg(n int, v interface{}) error {
 	if !in.isObject {
-		return nil
-	}
-	var args []interface{}
-	err := json.Unmarshal([]byte(in.Item.item.FuncArg), &args)
-	if err != nil {
-		out[0] = in.Item.item.FuncArg
-		return out
+		return fmt.Errorf("input is not an item")
 	}
-	for i, arg := range args {
-		out[i] = fmt.Sprintf("%v", arg)
+	args := decodeFuncArgs(in.Item.item.FuncArg)
+	if n < 0 || n >= len(args) {
+		return fmt.Errorf("missing func argument %d", n)
 	}
-	return out
+	return json.Unmarshal(args[n], v)
 }
 
 func (in *Input) Title() string {
@@ -194,3 +227,70 @@ func (in *Input) IsEmpty() bool  { return in.String() == "" }
 func (in *Input) IsLiveFeedback() bool { return in.isLiveFeedback }
 
 func (in *Input) Paths() []string { return in.paths }
+
+// Kind returns the kind the input was resolved to.
+func (in *Input) Kind() InputKind { return in.kind }
+
+// Accepted returns false if the input cannot be interpreted as any of the
+// kinds the action accepts (see Action.Accept), Kind is KindString then.
+func (in *Input) Accepted() bool { return !in.unaccepted }
+
+// Kinds returns all the kinds the input can be interpreted as.
+func (in *Input) Kinds() []InputKind { return in.kinds }
+
+// Is returns true if the input can be interpreted as kind k.
+func (in *Input) Is(k InputKind) bool {
+	for _, kind := range in.kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// URL returns the input as an url or nil if its Kind is not KindURL.
+func (in *Input) URL() *url.URL {
+	if in.text == nil || in.kind != KindURL {
+		return nil
+	}
+	return in.text.url
+}
+
+// Email returns the input as an email address or "" if its Kind is not
+// KindEmail.
+func (in *Input) Email() string {
+	if in.text == nil || in.kind != KindEmail {
+		return ""
+	}
+	return in.text.email
+}
+
+// Time returns the input as a time or the zero time if its Kind is not
+// KindDate.
+func (in *Input) Time() time.Time {
+	if in.text == nil || in.kind != KindDate {
+		return time.Time{}
+	}
+	return in.text.time
+}
+
+// Duration returns the input as a duration or 0 if its Kind is not
+// KindDuration.
+func (in *Input) Duration() time.Duration {
+	if in.text == nil || in.kind != KindDuration {
+		return 0
+	}
+	return in.text.duration
+}
+
+// Color returns the input as a lower case #rrggbb (or #rrggbbaa) color or ""
+// if its Kind is not KindColor.
+func (in *Input) Color() string {
+	if in.text == nil || in.kind != KindColor {
+		return ""
+	}
+	return in.text.color
+}
+
+// Command parses the input string as a mini command (see ParseCommand).
+func (in *Input) Command() *Command { return ParseCommand(in.String()) }
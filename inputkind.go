@@ -0,0 +1,166 @@
+package launchbar
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InputKind represents the kind of the input that LaunchBar passed to the action
+type InputKind int
+
+// Input kinds
+const (
+	KindEmpty    InputKind = iota // no input
+	KindString                    // any text
+	KindObject                    // an item passed back to the action as json
+	KindPaths                     // one or more files
+	KindNumber                    // an int or float, e.g. 42, -1.5
+	KindURL                       // an absolute url, e.g. https://example.com
+	KindEmail                     // an email address, e.g. john@example.com
+	KindDate                      // a date, e.g. 2015-06-20, 2015-06-20 10:30, today, tomorrow
+	KindDuration                  // a duration, e.g. 1h30m, 90s
+	KindColor                     // a hex color, e.g. #fff, #ff8800
+)
+
+var inputKindNames = []string{"empty", "string", "object", "paths", "number", "url", "email", "date", "duration", "color"}
+
+func (k InputKind) String() string {
+	if k < KindEmpty || k > KindColor {
+		return "kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return inputKindNames[k]
+}
+
+// defaultInputKinds is the order in which the kinds of the input are tried
+// when the action does not declare the kinds it accepts.
+var defaultInputKinds = []InputKind{KindEmpty, KindObject, KindPaths, KindNumber, KindColor, KindDuration, KindDate, KindEmail, KindURL, KindString}
+
+var (
+	reHexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	reDuration = regexp.MustCompile(`^(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))+$`)
+	reEmail    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+}
+
+// classified holds the typed values of a text input.
+type classified struct {
+	kinds    []InputKind
+	number   float64
+	url      *url.URL
+	email    string
+	time     time.Time
+	duration time.Duration
+	color    string
+}
+
+// ClassifyInput returns the kinds that s can be interpreted as. The result
+// always ends with KindString unless s is empty.
+func ClassifyInput(s string) []InputKind {
+	return classify(s, time.Now()).kinds
+}
+
+func classify(s string, now time.Time) *classified {
+	c := &classified{}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		c.kinds = []InputKind{KindEmpty}
+		return c
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		c.kinds = append(c.kinds, KindNumber)
+		c.number = f
+	}
+	if reHexColor.MatchString(s) {
+		c.kinds = append(c.kinds, KindColor)
+		c.color = expandHexColor(s)
+	}
+	if reDuration.MatchString(s) {
+		if d, err := time.ParseDuration(s); err == nil {
+			c.kinds = append(c.kinds, KindDuration)
+			c.duration = d
+		}
+	}
+	if t, ok := parseDate(s, now); ok {
+		c.kinds = append(c.kinds, KindDate)
+		c.time = t
+	}
+	if reEmail.MatchString(s) {
+		if addr, err := mail.ParseAddress(s); err == nil {
+			c.kinds = append(c.kinds, KindEmail)
+			c.email = addr.Address
+		}
+	}
+	if u, err := url.Parse(s); err == nil && !strings.ContainsAny(s, " \t") {
+		if u.Host != "" || (u.Opaque != "" && (u.Scheme == "mailto" || u.Scheme == "tel")) {
+			c.kinds = append(c.kinds, KindURL)
+			c.url = u
+		}
+	}
+	c.kinds = append(c.kinds, KindString)
+	return c
+}
+
+func expandHexColor(s string) string {
+	s = strings.ToLower(s[1:])
+	if len(s) == 3 || len(s) == 4 {
+		var b strings.Builder
+		for _, r := range s {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		s = b.String()
+	}
+	return "#" + s
+}
+
+func parseDate(s string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "now":
+		return now, true
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// resolveKind returns the first kind of accept that is in kinds. If accept is
+// empty the defaultInputKinds order is used.
+func resolveKind(kinds []InputKind, accept []InputKind) (InputKind, bool) {
+	if len(accept) == 0 {
+		accept = defaultInputKinds
+	}
+	for _, a := range accept {
+		for _, k := range kinds {
+			if a == k {
+				return k, true
+			}
+		}
+	}
+	return KindString, false
+}
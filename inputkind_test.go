@@ -0,0 +1,85 @@
+package launchbar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClassifyInput(t *testing.T) {
+	tests := []struct {
+		in    string
+		kinds []InputKind
+	}{
+		{"", []InputKind{KindEmpty}},
+		{"hello world", []InputKind{KindString}},
+		{"42", []InputKind{KindNumber, KindString}},
+		{"-1.5", []InputKind{KindNumber, KindString}},
+		{"#fff", []InputKind{KindColor, KindString}},
+		{"#FF8800", []InputKind{KindColor, KindString}},
+		{"1h30m", []InputKind{KindDuration, KindString}},
+		{"2015-06-20", []InputKind{KindDate, KindString}},
+		{"tomorrow", []InputKind{KindDate, KindString}},
+		{"john@example.com", []InputKind{KindEmail, KindString}},
+		{"https://example.com/a?b=c", []InputKind{KindURL, KindString}},
+		{"mailto:john@example.com", []InputKind{KindURL, KindString}},
+		{"example.com:8080", []InputKind{KindString}},
+		{`"1"`, []InputKind{KindString}},
+		{"[1]", []InputKind{KindString}},
+	}
+	for _, test := range tests {
+		if kinds := ClassifyInput(test.in); !reflect.DeepEqual(kinds, test.kinds) {
+			t.Errorf("ClassifyInput(%q) = %v, want %v", test.in, kinds, test.kinds)
+		}
+	}
+}
+
+func TestClassifyValues(t *testing.T) {
+	now := time.Date(2015, 6, 20, 10, 30, 0, 0, time.UTC)
+	if c := classify("#AbC", now); c.color != "#aabbcc" {
+		t.Errorf("bad color: %q", c.color)
+	}
+	if c := classify("90s", now); c.duration != 90*time.Second {
+		t.Errorf("bad duration: %v", c.duration)
+	}
+	if c := classify("tomorrow", now); !c.time.Equal(time.Date(2015, 6, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("bad date: %v", c.time)
+	}
+	if c := classify("https://example.com", now); c.url == nil || c.url.Host != "example.com" {
+		t.Errorf("bad url: %v", c.url)
+	}
+}
+
+func TestResolveKind(t *testing.T) {
+	kinds := []InputKind{KindNumber, KindString}
+	if k, _ := resolveKind(kinds, nil); k != KindNumber {
+		t.Errorf("expected number got %v", k)
+	}
+	if k, _ := resolveKind(kinds, []InputKind{KindURL, KindString}); k != KindString {
+		t.Errorf("expected string got %v", k)
+	}
+	if k, ok := resolveKind(kinds, []InputKind{KindURL}); ok || k != KindString {
+		t.Errorf("expected no match got %v %v", k, ok)
+	}
+}
+
+func TestInputAccessors(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Accept(KindString, KindURL)
+	in := NewInput(a, []string{"https://example.com"})
+	if in.Kind() != KindString || in.URL() != nil || !in.Accepted() {
+		t.Errorf("expected a string without url got %v %v", in.Kind(), in.URL())
+	}
+
+	a.Accept(KindColor, KindString)
+	in = NewInput(a, []string{"#fff"})
+	if in.Color() != "#ffffff" || in.Duration() != 0 || in.Email() != "" || !in.Time().IsZero() {
+		t.Errorf("bad accessors for %v: %q", in.Kind(), in.Color())
+	}
+
+	a.Accept(KindURL)
+	in = NewInput(a, []string{"42"})
+	if in.Accepted() || in.Kind() != KindString || !in.IsNumber() {
+		t.Errorf("expected an unaccepted number got %v %v", in.Kind(), in.Accepted())
+	}
+}
@@ -0,0 +1,115 @@
+package launchbar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/codegangsta/inject"
+)
+
+// FuncArgError is returned when the encoded arguments of an Item.Run call do
+// not match the parameters of the FuncMap func.
+type FuncArgError struct {
+	Func  string
+	Index int // index of the encoded argument
+	Type  reflect.Type
+	Err   error
+}
+
+func (e *FuncArgError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("func %q: missing argument %d (%s)", e.Func, e.Index, e.Type)
+	}
+	return fmt.Sprintf("func %q: argument %d: cannot use it as %s: %v", e.Func, e.Index, e.Type, e.Err)
+}
+
+// decodeFuncArgs splits the x-funcarg of an item into the encoded arguments.
+// Item.Run encodes the arguments as a json array, anything else is taken as
+// a single string argument.
+func decodeFuncArgs(s string) []json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	var args []json.RawMessage
+	if err := json.Unmarshal([]byte(s), &args); err == nil {
+		return args
+	}
+	b, _ := json.Marshal(s)
+	return []json.RawMessage{b}
+}
+
+// invokeFunc calls fn with its parameters filled in order: *Context,
+// context.Context and the pointer types mapped in the injector are injected,
+// every other parameter is decoded from the next encoded argument. A variadic
+// parameter takes the remaining arguments.
+func invokeFunc(inj inject.Injector, name string, fn Func, args []json.RawMessage) ([]reflect.Value, error) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("func %q: not a func: %T", name, fn)
+	}
+
+	in := make([]reflect.Value, 0, t.NumIn())
+	n := 0
+	for i := 0; i < t.NumIn(); i++ {
+		pt := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			for ; n < len(args); n++ {
+				v, err := decodeFuncArg(args[n], pt.Elem())
+				if err != nil {
+					return nil, &FuncArgError{name, n, pt.Elem(), err}
+				}
+				in = append(in, v)
+			}
+			break
+		}
+		if injectable(pt) {
+			if v := inj.Get(pt); v.IsValid() {
+				in = append(in, v)
+				continue
+			}
+		}
+		if n >= len(args) {
+			return nil, &FuncArgError{Func: name, Index: n, Type: pt}
+		}
+		v, err := decodeFuncArg(args[n], pt)
+		if err != nil {
+			return nil, &FuncArgError{name, n, pt, err}
+		}
+		in = append(in, v)
+		n++
+	}
+	return reflect.ValueOf(fn).Call(in), nil
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// injectable returns true if a parameter of type t is taken from the injector.
+// Interfaces other than context.Context and values are always decoded, the
+// injector would fill them with any mapped value that fits.
+func injectable(t reflect.Type) bool {
+	return t == contextType || t.Kind() == reflect.Ptr
+}
+
+func decodeFuncArg(raw json.RawMessage, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := dec.Decode(v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+// errorItem returns an item that shows the error.
+func (a *Action) errorItem(err error) *Item {
+	return NewItem(err.Error()).
+		SetSubtitle(a.T("error")).
+		SetIcon("at.obdev.LaunchBar:Caution")
+}
+
+// errorItems returns a single error item as the compiled output.
+func (a *Action) errorItems(err error) string {
+	return NewItems().Add(a.errorItem(err)).Compile()
+}
@@ -0,0 +1,82 @@
+package launchbar
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInvokeFunc(t *testing.T) {
+	a := newTestAction(t, nil)
+
+	var gotID int
+	var gotTags []string
+	fn := func(c *Context, id int, tags []string) string {
+		if c == nil {
+			t.Error("context is not injected")
+		}
+		gotID, gotTags = id, tags
+		return "ok"
+	}
+
+	i := NewItem("").Run("fn", 1500000, []string{"a", "b"})
+	vals, err := invokeFunc(a, "fn", fn, decodeFuncArgs(i.item.FuncArg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vals[0].String() != "ok" || gotID != 1500000 || !reflect.DeepEqual(gotTags, []string{"a", "b"}) {
+		t.Errorf("bad call: %v %v %v", vals[0], gotID, gotTags)
+	}
+
+	i = NewItem("").Run("fn", "x", []string{"a"})
+	if _, err := invokeFunc(a, "fn", fn, decodeFuncArgs(i.item.FuncArg)); err == nil || !strings.Contains(err.Error(), "argument 0") {
+		t.Errorf("expected a type mismatch error got %v", err)
+	}
+
+	i = NewItem("").Run("fn", 1)
+	if _, err := invokeFunc(a, "fn", fn, decodeFuncArgs(i.item.FuncArg)); err == nil || !strings.Contains(err.Error(), "missing argument 1") {
+		t.Errorf("expected a missing argument error got %v", err)
+	}
+
+	var rest []string
+	variadic := func(c *Context, names ...string) { rest = names }
+	if _, err := invokeFunc(a, "variadic", variadic, decodeFuncArgs(`["a","b","c"]`)); err != nil || len(rest) != 3 {
+		t.Errorf("variadic: %v %v", rest, err)
+	}
+}
+
+func TestFuncArgs(t *testing.T) {
+	a := newTestAction(t, []string{`{"x-func":"fn","x-funcarg":"[1500000,{\"a\":1},\"s\"]"}`})
+	args := a.Input.FuncArgs()
+	if args[0] != "1500000" || args[1] != `{"a":1}` || args[2] != "s" {
+		t.Errorf("bad args: %q", args)
+	}
+
+	a = newTestAction(t, []string{`{"x-func":"fn","x-funcarg":"plain string"}`})
+	if s := a.Input.FuncArg(); s != "plain string" {
+		t.Errorf("bad legacy arg: %q", s)
+	}
+}
+
+func TestInvokeFuncDecodesValues(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Map("mapped")
+	a.Map(errors.New("mapped"))
+
+	var gotAny interface{}
+	var gotString string
+	var gotErr error
+	var gotCtx context.Context
+	fn := func(c *Context, ctx context.Context, v interface{}, s string, err error) {
+		gotCtx, gotAny, gotString, gotErr = ctx, v, s, err
+	}
+	a.MapTo(context.Background(), (*context.Context)(nil))
+	if _, err := invokeFunc(a, "fn", fn, decodeFuncArgs(`[42,"arg",null]`)); err != nil {
+		t.Fatal(err)
+	}
+	if gotCtx == nil || gotAny != float64(42) || gotString != "arg" || gotErr != nil {
+		t.Errorf("bad call: %v %v %q %v", gotCtx, gotAny, gotString, gotErr)
+	}
+}
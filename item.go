@@ -1,6 +1,9 @@
 package launchbar
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"reflect"
+)
 
 // Func represents a generic type to pass functions.
 type Func interface{}
@@ -31,6 +34,87 @@ type Item struct {
 	run      Func // Runner func
 	render   Func // Renderer func
 	children []item
+
+	titleTmpl    *itemTemplate // see SetTitleTemplate
+	subtitleTmpl *itemTemplate
+}
+
+// clone returns a copy of the item that can be changed without affecting i,
+// see merge.
+func (i *Item) clone() *Item {
+	cp := *i
+	it := *i.item
+	if i.item.Data != nil {
+		it.Data = make(map[string]interface{}, len(i.item.Data))
+		for k, v := range i.item.Data {
+			it.Data[k] = v
+		}
+	}
+	it.Children = append([]*item(nil), i.item.Children...)
+	cp.item = &it
+	return &cp
+}
+
+// merge applies the changes made to the clone cp since base was cloned from i.
+// The changes made to i itself in the meantime, e.g. through a captured *Item,
+// are kept unless cp changed the same field.
+func (i *Item) merge(base, cp *Item) {
+	dst, old, src := reflect.ValueOf(i.item).Elem(), reflect.ValueOf(base.item).Elem(), reflect.ValueOf(cp.item).Elem()
+	for f := 0; f < dst.NumField(); f++ {
+		if dst.Type().Field(f).Name == "Data" {
+			continue
+		}
+		if !reflect.DeepEqual(src.Field(f).Interface(), old.Field(f).Interface()) {
+			dst.Field(f).Set(src.Field(f))
+		}
+	}
+	for k, v := range cp.item.Data {
+		if ov, ok := base.item.Data[k]; !ok || !reflect.DeepEqual(v, ov) {
+			if i.item.Data == nil {
+				i.item.Data = make(map[string]interface{})
+			}
+			i.item.Data[k] = v
+		}
+	}
+	for k := range base.item.Data {
+		if _, ok := cp.item.Data[k]; !ok {
+			delete(i.item.Data, k)
+		}
+	}
+
+	if cp.View != base.View {
+		i.View = cp.View
+	}
+	if !sameFunc(cp.match, base.match) {
+		i.match = cp.match
+	}
+	if !sameFunc(cp.run, base.run) {
+		i.run = cp.run
+	}
+	if !sameFunc(cp.render, base.render) {
+		i.render = cp.render
+	}
+	if !reflect.DeepEqual(cp.children, base.children) {
+		i.children = cp.children
+	}
+	if cp.titleTmpl != base.titleTmpl {
+		i.titleTmpl = cp.titleTmpl
+	}
+	if cp.subtitleTmpl != base.subtitleTmpl {
+		i.subtitleTmpl = cp.subtitleTmpl
+	}
+}
+
+// sameFunc returns true if f and g are the same func or both nil.
+func sameFunc(f, g Func) bool {
+	if f == nil || g == nil {
+		return f == nil && g == nil
+	}
+	fv, gv := reflect.ValueOf(f), reflect.ValueOf(g)
+	if fv.Kind() != reflect.Func || gv.Kind() != reflect.Func {
+		return reflect.DeepEqual(f, g)
+	}
+	return fv.Type() == gv.Type() && fv.Pointer() == gv.Pointer()
 }
 
 // NewItem initialize and returns a new Item
@@ -69,6 +153,7 @@ type item struct {
 	FuncArg  string                 `json:"x-funcarg,omitempty"`
 	Arg      string                 `json:"x-arg,omitempty"`
 	Data     map[string]interface{} `json:"x-data,omitempty"`
+	Sig      string                 `json:"x-sig,omitempty"`
 }
 
 // SetTitle sets the Item's title.
@@ -164,17 +249,17 @@ func (i *Item) Item() *item { return i.item }
 func (i *Item) Done() *View { return i.View }
 
 // Run sets the predefined func (see FuncMap) to be run with the optional
-// arguments when the user selects this item and hit Enter
+// arguments when the user selects this item and hit Enter.
+//
+// The arguments are encoded as json and passed to the parameters of the func
+// that are not injected, in order:
+//
+//	i.Run("tag", 42, []string{"a", "b"})
+//	FuncMap{"tag": func(c *Context, id int, tags []string) { ... }}
 func (i *Item) Run(f string, args ...interface{}) *Item {
 	i.item.FuncName = f
-	var ok bool
-	var s string
-	if len(args) == 1 {
-		if s, ok = args[0].(string); ok {
-			i.item.FuncArg = s
-		}
-	}
-	if len(args) > 1 || !ok {
+	i.item.FuncArg = ""
+	if len(args) > 0 {
 		b, err := json.Marshal(args)
 		if err == nil {
 			i.item.FuncArg = string(b)
@@ -7,40 +7,53 @@ package launchbar
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/DHowett/go-plist"
 	"github.com/bitly/go-simplejson"
 	"github.com/codegangsta/inject"
 )
 
-type infoPlist map[string]interface{}
-
 // Action represents a LaunchBar action
 type Action struct {
 	inject.Injector // Used for dependency injection
 	Config          *Config
 	Cache           *Cache
 	Input           *Input
-	Logger          *log.Logger
+	Logger          *log.Logger // writes to Log at LevelError
+	Log             *Logger
+	HTTP            *HTTPClient
+	Client          LaunchBarClient // used by ShowView, Push, Pop, ...; defaults to OsascriptClient, see DefaultClient
 	name            string
 	views           map[string]*View
 	items           []*Item
 	context         *Context
 	funcs           *FuncMap
-	info            infoPlist
+	info            *InfoPlist
+	infoErr         error
+	accept          []InputKind
+	commands        map[string]*CommandSpec
+	nav             []navEntry
+	public          map[string]bool
+	secret          []byte // the per-install key the x-func calls are signed with
+	middleware      []Middleware
+	i18n            *localizer
+	i18nOnce        sync.Once
 }
 
 // NewAction creates an empty action, ready to populate with views
 func NewAction(name string, config ConfigValues) *Action {
 	a := &Action{
 		Injector: inject.New(),
+		Client:   DefaultClient(),
 		name:     name,
 		views:    make(map[string]*View),
 		items:    make([]*Item, 0),
@@ -51,39 +64,86 @@ func NewAction(name string, config ConfigValues) *Action {
 		panic("you should specify 'actionDefaultScript' in the config")
 	}
 	defaultConfig := ConfigValues{
-		"debug":      false,
-		"autoUpdate": true,
+		"debug":       false,
+		"autoUpdate":  true,
+		"httpTimeout": 30.0,
+		"httpRetries": 2.0,
+		"logLevel":    "info",
+		"logMaxSize":  1048576.0,
+		"logBackups":  3.0,
+
+		"notifications": true,
+		"locale":        "",
+
+		"renderTimeout": 2.0,
+		"runTimeout":    30.0,
+		"streamTimeout": 0.0,
+		"timeoutMode":   "loading",
+
+		"diagnosticsLogLines": 50.0,
+		"sessionTimeout":      60.0,
 	}
 	for k, v := range config {
 		defaultConfig[k] = v
 	}
 	a.Config = NewConfigDefaults(a.SupportPath(), defaultConfig)
+	if a.Config.Get("view") != nil {
+		// the current view used to be kept in the config, see ShowView
+		a.Config.Delete("view")
+	}
 
 	a.Cache = NewCache(a.CachePath())
-	fd, err := os.OpenFile(path.Join(a.SupportPath(), "error.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY|os.O_SYNC, 0644)
-	if err != nil {
-		fd = os.Stderr
+	var w io.Writer = os.Stderr
+	if fd, err := OpenRotatingFile(path.Join(a.SupportPath(), "error.log"), a.Config.GetInt("logMaxSize"), int(a.Config.GetInt("logBackups"))); err == nil {
+		w = fd
+	}
+	a.Log = NewLogger(w, a.logLevel())
+	a.Logger = a.Log.StdLogger(LevelError)
+
+	if key, err := loadSecret(a.SupportPath()); key != nil {
+		a.secret = key
+		if err != nil {
+			a.Log.Warn("cannot save the secret, signed items expire with this run", "error", err)
+		}
+	} else {
+		a.Log.Error("cannot create the secret", "error", err)
 	}
-	a.Logger = log.New(fd, "", 0)
 	c := &Context{
 		Action: a,
 		Config: a.Config,
 		Cache:  a.Cache,
 		Logger: a.Logger,
+		Log:    a.Log,
 	}
 	a.context = c
 	a.Map(c)
 
-	data, err := ioutil.ReadFile(path.Join(a.ActionPath(), "Contents", "Info.plist"))
-	if err != nil {
-		a.Logger.Println(err)
-		panic(err)
+	a.info, a.infoErr = ReadInfoPlist(path.Join(a.ActionPath(), "Contents", "Info.plist"))
+	if a.infoErr != nil {
+		// Run shows the error
+		a.Log.Error("cannot read the Info.plist", "error", a.infoErr)
+		a.info = &InfoPlist{}
+	} else if err := a.info.Validate(); err != nil {
+		a.Log.Warn("invalid Info.plist", "error", err)
 	}
-	_, err = plist.Unmarshal(data, &a.info)
-	if err != nil {
-		a.Logger.Println(err)
-		panic(err)
+
+	if v := a.info.CFBundleVersion; v != "" {
+		a.Log.fields = append(a.Log.fields, "version", v)
 	}
+
+	a.HTTP = NewHTTPClient(a)
+	a.Cache.HTTP = a.HTTP
+	c.HTTP = a.HTTP
+	return a
+}
+
+// Accept declares the kinds of input the action accepts, in the order of
+// preference. An ambiguous input is resolved to the first of them it can be
+// interpreted as, e.g. with Accept(KindURL, KindString) "#fff" is a string.
+//
+// Accept must be called before Init.
+func (a *Action) Accept(kinds ...InputKind) *Action {
+	a.accept = append([]InputKind{KindEmpty, KindObject}, kinds...)
 	return a
 }
 
@@ -98,14 +158,23 @@ func (a *Action) Init(m ...FuncMap) *Action {
 	a.Input = in
 	a.context.Input = in
 
-	// TODO: needs good documentation
+	// Stream producers run in a detached process started by Stream.
+	if in.hasFunc && in.Item.item.FuncName == streamFunc {
+		a.runStream(in)
+		os.Exit(0)
+	}
+
+	// The update func runs in a detached process started by Run, see update().
+	// It's only accepted signed unless the action makes it public.
 	if in.hasFunc && in.Item.Item().FuncName == "update" {
 		updateFn := Func(update)
 		if fn, ok := (*a.funcs)[in.Item.item.FuncName]; ok {
 			updateFn = fn
 		}
 
-		vals, err := a.Invoke(updateFn)
+		vals, err := a.invoke(&Call{Kind: CallFunc, Name: "update", Item: in.Item, Func: updateFn}, func(inj inject.Injector) ([]reflect.Value, error) {
+			return inj.Invoke(updateFn)
+		})
 
 		if err != nil {
 			a.Logger.Fatalln(err)
@@ -117,9 +186,7 @@ func (a *Action) Init(m ...FuncMap) *Action {
 		if !ok {
 			a.Logger.Fatalf("update function: expected string got: %#v", vals[0].Interface())
 		}
-		if a.InDev() {
-			a.Logger.Println(out)
-		}
+		a.Log.Debug("update function output", "out", out)
 		json, err := simplejson.NewJson([]byte(out))
 		if err != nil {
 			a.Logger.Fatalf("update function should return a valid json string: %q (%v)", out, err)
@@ -179,7 +246,7 @@ func (a *Action) Init(m ...FuncMap) *Action {
 			if err != nil {
 				a.Logger.Fatalf("update function bad output: %q (%s)", out, "'description' is not string")
 			}
-			a.Logger.Println(e, ":", desc)
+			a.Log.Warn("update check failed", "error", e, "description", desc)
 		}
 
 		os.Exit(0)
@@ -190,6 +257,9 @@ func (a *Action) Init(m ...FuncMap) *Action {
 
 // Run returns the compiled output of views. You must call Init first
 func (a *Action) Run() string {
+	if a.infoErr != nil {
+		return a.errorItems(a.infoErr)
+	}
 
 	// Creating item to handle update
 	i := a.GetView("main").NewItem("")
@@ -206,7 +276,7 @@ func (a *Action) Run() string {
 			return
 		}
 		newversion := Version(updateInfo["version"])
-		c.Self.SetTitle(fmt.Sprintf("New Version Available: v%s (I'm v%s)", newversion, oldversion))
+		c.Self.SetTitle(c.T("update.available", newversion, oldversion))
 	})
 	i.SetMatch(func(c *Context) bool {
 		oldversion := c.Action.Version()
@@ -228,102 +298,94 @@ func (a *Action) Run() string {
 			return nil
 		}
 		items := NewItems()
-		items.Add(NewItem(fmt.Sprintf("Download %s", path.Base(updateInfo["download"]))).SetURL(updateInfo["download"]))
-		for _, line := range strings.Split(updateInfo["changelog"], "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			items.Add(NewItem(line).SetIcon("at.obdev.LaunchBar:ContentsTemplate"))
-		}
-		homepage := ""
-		if desc := a.info["LBDescription"]; desc != nil {
-			if web := desc.(map[string]interface{})["LBWebsite"]; web != nil {
-				if s, ok := web.(string); ok {
-					homepage = s
-				}
+		items.Add(NewItem(c.T("update.download", path.Base(updateInfo["download"]))).SetURL(updateInfo["download"]))
+		cl := parseChangelog(updateInfo["changelog"])
+		qlurl := ""
+		if cl.Raw != "" {
+			p := path.Join(c.Action.CachePath(), "changelog.html")
+			if err := ioutil.WriteFile(p, []byte(cl.HTML(fmt.Sprintf("%s v%s", c.Action.name, newversion))), 0644); err != nil {
+				c.Logger.Println(err)
+			} else {
+				qlurl = "file://" + p
 			}
 		}
-		if homepage != "" {
-			items.Add(NewItem("Open Homepage").SetURL(homepage))
+		items.Add(*cl.Items(oldversion, qlurl)...)
+		if homepage := a.info.LBDescription.LBWebsite; homepage != "" {
+			items.Add(NewItem(c.T("update.homepage")).SetURL(homepage))
 		}
 		return items
 	})
 
+	if a.GetView(DiagnosticsView) == nil {
+		a.newDiagnosticsView()
+	}
+	back := a.newBackItem()
+	a.touchSession()
+	a.context.ViewParams = a.currentNav().Params
+	if a.context.ViewParams == nil {
+		a.context.ViewParams = ViewParams{}
+	}
+
 	in := a.Input
 	if in.IsObject() {
+		if in.funcRejected {
+			a.Log.Warn("rejected func call", "func", in.Item.item.FuncName)
+			return a.errorItems(fmt.Errorf("%s: %v", in.Item.item.FuncName, ErrUnsignedFunc))
+		}
 		if in.hasFunc {
 			// I'm not sure!
 			a.context.Self = in.Item
 			if fn, ok := (*a.funcs)[in.Item.item.FuncName]; ok {
-				vals, err := a.Invoke(fn)
+				name := in.Item.item.FuncName
+				vals, err := a.invoke(&Call{Kind: CallFunc, Name: name, Item: in.Item, Func: fn}, func(inj inject.Injector) ([]reflect.Value, error) {
+					return invokeFunc(inj, name, fn, decodeFuncArgs(in.Item.item.FuncArg))
+				})
 				if err != nil {
-					a.Logger.Fatalln(err)
+					a.Log.Error("cannot invoke func", "error", err)
+					return a.errorItems(err)
 				}
-				if len(vals) > 0 {
-					if vals[0].Interface() != nil {
-						s := ""
-						switch res := vals[0].Interface().(type) {
-
-						case Items:
-							s = res.Compile()
-						case string:
-							s = res
-						case *View:
-							s = res.Compile()
-						case *Items:
-							s = res.Compile()
-						}
-						return s
-					}
-				}
-				return ""
+				return a.compileOutput(vals)
 			}
 		} else {
 			if item := a.GetItem(in.Item.item.ID); item != nil {
 				a.context.Self = item
 				if item.run != nil {
-					vals, err := a.Invoke(item.run)
+					vals, err := a.invoke(&Call{Kind: CallRun, Item: item, Func: item.run}, func(inj inject.Injector) ([]reflect.Value, error) {
+						return inj.Invoke(item.run)
+					})
+					if IsTimeout(err) {
+						a.Log.Warn("runner timed out", "error", err)
+						return a.errorItems(err)
+					}
 					if err != nil {
 						a.Logger.Fatalln(err)
 					}
-					if len(vals) > 0 {
-						if vals[0].Interface() != nil {
-							var s string
-							if out, ok := vals[0].Interface().(Items); ok {
-								s = out.Compile()
-							} else {
-								s = vals[0].Interface().(*Items).Compile()
-							}
-							return s
-						}
-					}
-					return ""
+					return a.compileOutput(vals)
 				}
 			}
 		}
 	}
-	// TODO: if a.GetView(view) == nil inform the developer
-	view := a.Config.GetString("view")
-
-	if view == "" {
+	view := a.CurrentView()
+	if a.GetView(view) == nil {
+		a.Log.Error("view is not defined", "view", view)
 		view = "main"
 	}
 
+	if view == "main" && a.IsShiftKey() && a.IsCommandKey() {
+		view = DiagnosticsView
+	}
+
 	if view == "main" {
 		// check for updates
 		checkForUpdates := false
-		updateLink := ""
-		if v := a.info["LBDescription"].(map[string]interface{})["LBUpdate"]; v != nil {
-			updateLink = v.(string)
-		}
+		updateLink := a.info.LBDescription.LBUpdate
 		// lastUpdate := a.Config.GetInt("lastUpdate")
 		var lastUpdate time.Time
 		if updateLink != "" {
 			// TODO: Watch this, IsControlKey, IsOptionKey does not work in LB6102
 			if a.IsShiftKey() && a.IsOptionKey() {
 				// TODO: notify the user
-				a.Logger.Println("Force update.")
+				a.Log.Info("force update")
 				checkForUpdates = true
 			} else if a.Config.GetBool("autoUpdate") {
 				if _, err := a.Cache.Get("lastUpdate", &lastUpdate); err == nil || err == ErrCacheDoesNotExists {
@@ -335,36 +397,112 @@ func (a *Action) Run() string {
 		}
 		if checkForUpdates {
 			if a.InDev() {
-				a.Logger.Println("Checking for update...")
-				out, _ := exec.Command(os.Args[0], `{"x-func":"update"}`).CombinedOutput()
+				a.Log.Debug("checking for update")
+				out, _ := exec.Command(os.Args[0], a.signedFuncCall("update")).CombinedOutput()
 				if s := strings.TrimSpace(string(out)); s != "" {
-					a.Logger.Println(s)
+					a.Log.Debug("update check output", "out", s)
 				}
 			} else {
-				exec.Command(os.Args[0], `{"x-func":"update"}`).Start()
+				exec.Command(os.Args[0], a.signedFuncCall("update")).Start()
 			}
 		}
 	}
 
+	if !in.IsObject() && len(a.commands) > 0 {
+		cmd := ParseCommand(in.String())
+		if spec := a.commands[cmd.Name]; spec != nil {
+			a.context.Command = cmd
+			if spec.Func != "" {
+				fn, ok := (*a.funcs)[spec.Func]
+				if !ok {
+					err := fmt.Errorf("command %q: unknown func %q", spec.Name, spec.Func)
+					a.Log.Error("cannot route command", "error", err)
+					return a.errorItems(err)
+				}
+				vals, err := a.invoke(&Call{Kind: CallFunc, Name: spec.Func, Func: fn}, func(inj inject.Injector) ([]reflect.Value, error) {
+					return inj.Invoke(fn)
+				})
+				if IsTimeout(err) {
+					a.Log.Warn("command timed out", "error", err)
+					return a.errorItems(err)
+				}
+				if err != nil {
+					a.Log.Error("cannot invoke command", "command", spec.Name, "error", err)
+					return a.errorItems(err)
+				}
+				return a.compileOutput(vals)
+			}
+			if spec.View != "" {
+				if a.GetView(spec.View) == nil {
+					err := fmt.Errorf("command %q: view %q is not defined", spec.Name, spec.View)
+					a.Log.Error("cannot route command", "error", err)
+					return a.errorItems(err)
+				}
+				view = spec.View
+			}
+		}
+	}
+
+	if err := a.GetView(view).checkParams(a.context.ViewParams); err != nil {
+		a.Log.Error("cannot show view", "error", err)
+		return a.errorItems(err)
+	}
+
 	w := a.GetView("*")
-	out := a.GetView(view).Join(w).Compile()
-	return out
+	nav := &View{Action: a, Name: view, Items: Items{back}}
+	out := a.GetView(view).Join(w).Join(nav)
+	out.completions = len(a.commands) > 0 && !in.IsObject() && view != DiagnosticsView
+	return out.Compile()
+}
+
+// rerun re-triggers the action in LaunchBar with arg as the input. An empty
+// arg reruns the action without input.
+func (a *Action) rerun(arg string) {
+	if err := a.Client.PerformAction(a.name, arg); err != nil {
+		a.Log.Error("cannot rerun the action", "error", err)
+	}
+}
+
+// compileOutput returns the compiled output of a Runner or FuncMap func with
+// the func calls of the items signed. A string is returned as is.
+func (a *Action) compileOutput(vals []reflect.Value) string {
+	if len(vals) == 0 || vals[0].Interface() == nil {
+		return ""
+	}
+	switch res := vals[0].Interface().(type) {
+	case Items:
+		a.signItems(res.getItems())
+		return res.Compile()
+	case string:
+		return a.signJSON(res)
+	case *View:
+		return res.Compile()
+	case *Items:
+		if res != nil {
+			a.signItems(res.getItems())
+		}
+		return res.Compile()
+	}
+	return ""
 }
 
 // ShowView reruns the LaunchBar with the specified view.
 //
-// Use this when your LiveFeedback is enabled and you want to show another view
+// Use this when your LiveFeedback is enabled and you want to show another view.
+// The view is pushed on the navigation stack (see Push), showing "main" starts
+// over with an empty stack.
 func (a *Action) ShowView(v string) {
-	a.Config.Set("view", v)
-	exec.Command("osascript", "-e", fmt.Sprintf(`tell application "LaunchBar"
-       remain active
-       perform action "%s"
-       end tell`, a.name)).Start()
+	if v == "main" {
+		a.setNavStack(nil)
+		a.rerun("")
+		return
+	}
+	a.Push(v, nil)
 }
 
 // NewView created a new view ready to populate with Items
 func (a *Action) NewView(name string) *View {
-	v := &View{a, name, make(Items, 0)}
+	v := &View{Action: a, Name: name, Items: make(Items, 0)}
 	a.views[name] = v
 	return v
 }
@@ -388,13 +526,27 @@ func (a *Action) GetItem(id int) *Item {
 	return nil
 }
 
+// logLevel returns the log level from the config, debug if the action is in
+// development or LBDebugLogEnabled is set.
+func (a *Action) logLevel() LogLevel {
+	if a.IsDebug() || a.InDev() || a.Config.GetBool("debug") {
+		return LevelDebug
+	}
+	level, _ := ParseLogLevel(a.Config.GetString("logLevel"))
+	return level
+}
+
 // InDev returns true if the config.indev is true
 func (a *Action) InDev() bool { return a.Config.GetBool("indev") }
 
 // Info.plist variables
 
+// Info returns the Info.plist of the action. It's empty if the file cannot be
+// read, the error is shown by Run.
+func (a *Action) Info() *InfoPlist { return a.info }
+
 // Varsion returns Action version specified by CFBundleVersion key in Info.plist
-func (a *Action) Version() Version { return Version(a.info["CFBundleVersion"].(string)) }
+func (a *Action) Version() Version { return Version(a.info.CFBundleVersion) }
 
 // LaunchBar provided variabled
 
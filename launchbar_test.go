@@ -0,0 +1,72 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.test</string>
+	<key>CFBundleName</key>
+	<string>Test</string>
+	<key>CFBundleVersion</key>
+	<string>1.0</string>
+	<key>LBDescription</key>
+	<dict>
+		<key>LBAuthor</key>
+		<string>Test</string>
+	</dict>
+	<key>LBScripts</key>
+	<dict>
+		<key>LBDefaultScript</key>
+		<dict>
+			<key>LBScriptName</key>
+			<string>default.sh</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+// newTestAction creates an action bundle in a temp dir, points the LB_*
+// variables to it and returns the action initialized with args.
+func newTestAction(t *testing.T, args []string, m ...FuncMap) *Action {
+	dir, err := ioutil.TempDir("", "lbaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	actionPath := path.Join(dir, "Test.lbaction")
+	for _, p := range []string{path.Join(actionPath, "Contents", "Scripts"), path.Join(dir, "cache"), path.Join(dir, "support")} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(path.Join(actionPath, "Contents", "Info.plist"), []byte(testInfoPlist), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{
+		"LB_ACTION_PATH":  actionPath,
+		"LB_CACHE_PATH":   path.Join(dir, "cache"),
+		"LB_SUPPORT_PATH": path.Join(dir, "support"),
+	} {
+		t.Setenv(k, v)
+	}
+
+	a := NewAction("Test", ConfigValues{"actionDefaultScript": "default.sh", "autoUpdate": false})
+	a.Client = &RecordingClient{}
+	a.funcs = &FuncMap{}
+	if m != nil {
+		*a.funcs = m[0]
+	}
+	a.Input = NewInput(a, args)
+	a.context.Input = a.Input
+	return a
+}
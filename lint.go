@@ -0,0 +1,252 @@
+package launchbar
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LintSeverity is the severity of a LintIssue.
+type LintSeverity int
+
+// Lint severities
+const (
+	LintWarning LintSeverity = iota // the action works, but probably not as intended
+	LintError                       // the action or its update is broken
+)
+
+func (s LintSeverity) String() string {
+	if s == LintError {
+		return "error"
+	}
+	return "warning"
+}
+
+// LintIssue is a problem found by LintBundle.
+type LintIssue struct {
+	Severity LintSeverity
+	File     string // relative to the bundle, e.g. Contents/Info.plist
+	Key      string // the key path in the file, e.g. LBScripts.LBDefaultScript, if any
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	loc := i.File
+	if i.Key != "" {
+		loc += ": " + i.Key
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, i.Severity, i.Message)
+}
+
+// LintErrors returns the number of issues with the error severity.
+func LintErrors(issues []LintIssue) int {
+	n := 0
+	for _, i := range issues {
+		if i.Severity == LintError {
+			n++
+		}
+	}
+	return n
+}
+
+const infoPlistFile = "Contents/Info.plist"
+
+// LintBundle checks the action bundle at dir: the Info.plist against the
+// InfoPlist model, the scripts of LBScripts and the extra scripts (e.g. the
+// actionDefaultScript of the config) in Contents/Scripts, the icon and the
+// localized strings in Contents/Resources. The error is only returned if dir
+// is not a bundle.
+func LintBundle(dir string, scripts ...string) ([]LintIssue, error) {
+	if fi, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not an action bundle", dir)
+	}
+
+	var issues []LintIssue
+	add := func(sev LintSeverity, file, key, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{sev, file, key, fmt.Sprintf(format, args...)})
+	}
+
+	info, err := ReadInfoPlist(path.Join(dir, infoPlistFile))
+	if err != nil {
+		add(LintError, infoPlistFile, "", "%v", err)
+		return issues, nil
+	}
+	for _, p := range info.validate() {
+		add(LintError, infoPlistFile, p.key, "%s", p.msg)
+	}
+	for _, key := range unknownInfoKeys(info) {
+		add(LintWarning, infoPlistFile, key, "is not a known key, check the spelling")
+	}
+	for _, id := range info.LBRequiredApplication {
+		if !reBundleIdentifier.MatchString(id) {
+			add(LintWarning, infoPlistFile, "LBRequiredApplication", "%q is not a bundle identifier", id)
+		}
+	}
+	if d := info.LBDescription; d.LBUpdate != "" && d.LBDownload == "" {
+		add(LintWarning, infoPlistFile, "LBDescription.LBDownload", "is missing, the update feed at LBUpdate must set it")
+	}
+
+	checkScript := func(name, key string) {
+		p := path.Join("Contents", "Scripts", name)
+		fi, err := os.Stat(path.Join(dir, p))
+		switch {
+		case err != nil:
+			add(LintError, infoPlistFile, key, "script %s does not exist", p)
+		case fi.IsDir():
+			add(LintError, infoPlistFile, key, "script %s is a directory", p)
+		case fi.Mode()&0111 == 0 && !isAppleScript(name):
+			add(LintWarning, p, "", "is not executable")
+		}
+	}
+	for _, s := range info.LBScripts.list() {
+		if s.script.LBScriptName != "" {
+			checkScript(s.script.LBScriptName, "LBScripts."+s.name+".LBScriptName")
+		}
+	}
+	for _, name := range scripts {
+		if name != "" {
+			checkScript(name, "")
+		}
+	}
+
+	if icon := info.CFBundleIconFile; icon != "" && isIconFile(icon) {
+		found := false
+		for _, ext := range []string{"", ".icns", ".png", ".pdf"} {
+			if _, err := os.Stat(path.Join(dir, "Contents", "Resources", icon+ext)); err == nil {
+				found = true
+			}
+		}
+		if !found {
+			add(LintWarning, infoPlistFile, "CFBundleIconFile", "%s is not in Contents/Resources", icon)
+		}
+	}
+
+	lprojs, _ := ioutil.ReadDir(path.Join(dir, "Contents", "Resources"))
+	for _, d := range lprojs {
+		if !d.IsDir() || path.Ext(d.Name()) != ".lproj" {
+			continue
+		}
+		if _, err := loadCatalog(path.Join(dir, "Contents", "Resources", d.Name())); err != nil {
+			add(LintError, path.Join("Contents", "Resources", d.Name()), "", "%v", err)
+		}
+	}
+	return issues, nil
+}
+
+// Lint checks the bundle of the action, see LintBundle.
+func (a *Action) Lint() ([]LintIssue, error) {
+	return LintBundle(a.ActionPath(), a.Config.GetString("actionDefaultScript"))
+}
+
+// LintUpdateFeed checks the update feed (the plist at LBUpdate) of the bundle
+// with the Info.plist info: update needs a valid CFBundleVersion and the
+// LBDownload link.
+func LintUpdateFeed(info *InfoPlist, feed []byte) []LintIssue {
+	const file = "LBUpdate"
+	var issues []LintIssue
+	add := func(sev LintSeverity, key, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{sev, file, key, fmt.Sprintf(format, args...)})
+	}
+
+	f, err := ParseInfoPlist(feed)
+	if err != nil {
+		add(LintError, "", "%v", err)
+		return issues
+	}
+	v := Version(f.CFBundleVersion)
+	switch {
+	case v == "":
+		add(LintError, "CFBundleVersion", "is missing")
+	case !v.Valid():
+		add(LintError, "CFBundleVersion", "%q is not a version, e.g. 1.2.3", v)
+	case v.Less(Version(info.CFBundleVersion)):
+		add(LintWarning, "CFBundleVersion", "%s is older than the bundle version %s", v, info.CFBundleVersion)
+	}
+	if f.LBDescription.LBDownload == "" {
+		add(LintError, "LBDescription.LBDownload", "is missing")
+	}
+	if f.CFBundleIdentifier != "" && f.CFBundleIdentifier != info.CFBundleIdentifier {
+		add(LintWarning, "CFBundleIdentifier", "%q is not the bundle identifier %q", f.CFBundleIdentifier, info.CFBundleIdentifier)
+	}
+	return issues
+}
+
+// unknownInfoKeys returns the LB* keys of the Info.plist that are not in the
+// InfoPlist model, usually typos.
+func unknownInfoKeys(info *InfoPlist) []string {
+	var unknown []string
+	known := plistKeys(reflect.TypeOf(InfoPlist{}))
+	for key, val := range info.Raw {
+		if !strings.HasPrefix(key, "LB") {
+			continue
+		}
+		if !known[key] {
+			unknown = append(unknown, key)
+			continue
+		}
+		var sub reflect.Type
+		switch key {
+		case "LBDescription":
+			sub = reflect.TypeOf(LBDescription{})
+		case "LBScripts":
+			sub = reflect.TypeOf(LBScripts{})
+		}
+		m, ok := val.(map[string]interface{})
+		if !ok || sub == nil {
+			continue
+		}
+		subKnown := plistKeys(sub)
+		for k, v := range m {
+			if !subKnown[k] {
+				unknown = append(unknown, key+"."+k)
+				continue
+			}
+			if script, ok := v.(map[string]interface{}); ok && key == "LBScripts" {
+				scriptKnown := plistKeys(reflect.TypeOf(LBScript{}))
+				for sk := range script {
+					if !scriptKnown[sk] {
+						unknown = append(unknown, key+"."+k+"."+sk)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// plistKeys returns the keys of the plist tags of the struct type.
+func plistKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("plist"), ",")[0]
+		if tag != "" && tag != "-" {
+			keys[tag] = true
+		}
+	}
+	return keys
+}
+
+func isAppleScript(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".scpt" || ext == ".applescript" || ext == ".scptd"
+}
+
+// isIconFile returns false for icons LaunchBar resolves itself, e.g.
+// at.obdev.LaunchBar:Search or com.apple.Safari.
+func isIconFile(icon string) bool {
+	if strings.Contains(icon, ":") {
+		return false
+	}
+	switch path.Ext(icon) {
+	case "", ".icns", ".png", ".pdf":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+const lintInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.lint</string>
+	<key>CFBundleName</key>
+	<string>Lint</string>
+	<key>CFBundleVersion</key>
+	<string>1.0b</string>
+	<key>CFBundleIconFile</key>
+	<string>icon</string>
+	<key>LBRequiredApplication</key>
+	<string>Safari</string>
+	<key>LBDescription</key>
+	<dict>
+		<key>LBUpdate</key>
+		<string>https://example.com/update.plist</string>
+		<key>LBAuthr</key>
+		<string>Sam</string>
+	</dict>
+	<key>LBScripts</key>
+	<dict>
+		<key>LBDefaultScript</key>
+		<dict>
+			<key>LBScriptName</key>
+			<string>default.sh</string>
+			<key>LBRunsInBackground</key>
+			<true/>
+		</dict>
+		<key>LBSuggestionsScript</key>
+		<dict>
+			<key>LBScriptName</key>
+			<string>suggest.sh</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestLintBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, data := range map[string]string{
+		"Contents/Info.plist":                             lintInfoPlist,
+		"Contents/Scripts/default.sh":                     "#!/bin/sh\n",
+		"Contents/Resources/de.lproj/Localizable.strings": `"a" = "b"`,
+	} {
+		os.MkdirAll(path.Join(dir, path.Dir(name)), 0755)
+		if err := ioutil.WriteFile(path.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues, err := LintBundle(dir, "items.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`Contents/Info.plist: CFBundleVersion: error: "1.0b" is not a version, e.g. 1.2.3`,
+		`Contents/Info.plist: LBDescription.LBAuthr: warning: is not a known key, check the spelling`,
+		`Contents/Info.plist: LBScripts.LBDefaultScript.LBRunsInBackground: warning: is not a known key, check the spelling`,
+		`Contents/Info.plist: LBRequiredApplication: warning: "Safari" is not a bundle identifier`,
+		`Contents/Info.plist: LBDescription.LBDownload: warning: is missing, the update feed at LBUpdate must set it`,
+		`Contents/Scripts/default.sh: warning: is not executable`,
+		`Contents/Info.plist: LBScripts.LBSuggestionsScript.LBScriptName: error: script Contents/Scripts/suggest.sh does not exist`,
+		`Contents/Info.plist: error: script Contents/Scripts/items.sh does not exist`,
+		`Contents/Info.plist: CFBundleIconFile: warning: icon is not in Contents/Resources`,
+		`Contents/Resources/de.lproj: error: Localizable.strings: offset 9: expected ';'`,
+	}
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if n := LintErrors(issues); n != 4 {
+		t.Errorf("LintErrors = %d", n)
+	}
+
+	if _, err := LintBundle(path.Join(dir, "nope")); err == nil {
+		t.Errorf("LintBundle of a missing dir did not fail")
+	}
+}
+
+func TestLintUpdateFeed(t *testing.T) {
+	info := &InfoPlist{CFBundleIdentifier: "com.example.lint", CFBundleVersion: "1.2"}
+	tests := []struct {
+		feed string
+		want []string
+	}{
+		{`<plist><dict><key>CFBundleVersion</key><string>1.3</string><key>LBDescription</key><dict><key>LBDownload</key><string>https://example.com/a.zip</string></dict></dict></plist>`, nil},
+		{`<plist><dict><key>CFBundleVersion</key><string>1.1</string><key>CFBundleIdentifier</key><string>com.example.other</string></dict></plist>`, []string{
+			"LBUpdate: CFBundleVersion: warning: 1.1 is older than the bundle version 1.2",
+			"LBUpdate: LBDescription.LBDownload: error: is missing",
+			`LBUpdate: CFBundleIdentifier: warning: "com.example.other" is not the bundle identifier "com.example.lint"`,
+		}},
+		{`<plist><dict/></plist>`, []string{
+			"LBUpdate: CFBundleVersion: error: is missing",
+			"LBUpdate: LBDescription.LBDownload: error: is missing",
+		}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, i := range LintUpdateFeed(info, []byte(test.feed)) {
+			got = append(got, i.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", test.feed, strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package launchbar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel represents the severity of a log entry
+type LogLevel int
+
+// Log levels
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+func (l LogLevel) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel returns the LogLevel for s (debug, info, warn or error).
+func ParseLogLevel(s string) (LogLevel, error) {
+	for i, name := range logLevelNames {
+		if strings.EqualFold(s, name) {
+			return LogLevel(i), nil
+		}
+	}
+	if strings.EqualFold(s, "warning") {
+		return LevelWarn, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %q", s)
+}
+
+// Logger is a leveled logger that writes one line per entry in key=value format:
+//
+//	time=2015-06-20T10:00:00+02:00 level=info version=1.0 msg="fetched" url=http://...
+type Logger struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	level  *LogLevel
+	fields []interface{}
+}
+
+// NewLogger initializes a new Logger that writes the entries with level or
+// higher to w.
+func NewLogger(w io.Writer, level LogLevel) *Logger {
+	return &Logger{w: w, mu: &sync.Mutex{}, level: &level}
+}
+
+// With returns a Logger that adds the key-value pairs to each entry. The
+// returned Logger shares the writer and the level with l.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &Logger{w: l.w, mu: l.mu, level: l.level, fields: fields}
+}
+
+// SetLevel sets the minimum level of the entries that are written.
+func (l *Logger) SetLevel(level LogLevel) { l.mu.Lock(); *l.level = level; l.mu.Unlock() }
+
+// Level returns the minimum level of the entries that are written.
+func (l *Logger) Level() LogLevel { l.mu.Lock(); defer l.mu.Unlock(); return *l.level }
+
+// Debug logs msg with the key-value pairs at LevelDebug.
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.Log(LevelDebug, msg, kv...) }
+
+// Info logs msg with the key-value pairs at LevelInfo.
+func (l *Logger) Info(msg string, kv ...interface{}) { l.Log(LevelInfo, msg, kv...) }
+
+// Warn logs msg with the key-value pairs at LevelWarn.
+func (l *Logger) Warn(msg string, kv ...interface{}) { l.Log(LevelWarn, msg, kv...) }
+
+// Error logs msg with the key-value pairs at LevelError.
+func (l *Logger) Error(msg string, kv ...interface{}) { l.Log(LevelError, msg, kv...) }
+
+// Log writes an entry if level is enabled.
+func (l *Logger) Log(level LogLevel, msg string, kv ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < *l.level {
+		return
+	}
+	var b bytes.Buffer
+	b.WriteString("time=")
+	b.WriteString(time.Now().Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	writeLogFields(&b, l.fields)
+	b.WriteString(" msg=")
+	b.WriteString(logValue(msg))
+	writeLogFields(&b, kv)
+	b.WriteByte('\n')
+	l.w.Write(b.Bytes())
+}
+
+func writeLogFields(b *bytes.Buffer, kv []interface{}) {
+	for i := 0; i < len(kv); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(fmt.Sprint(kv[i]))
+		b.WriteByte('=')
+		if i+1 < len(kv) {
+			b.WriteString(logValue(fmt.Sprint(kv[i+1])))
+		} else {
+			b.WriteString(`"(MISSING)"`)
+		}
+	}
+}
+
+func logValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// StdLogger returns a *log.Logger that writes each line to l at level.
+func (l *Logger) StdLogger(level LogLevel) *log.Logger {
+	return log.New(&stdLogAdapter{l, level}, "", 0)
+}
+
+type stdLogAdapter struct {
+	l     *Logger
+	level LogLevel
+}
+
+func (a *stdLogAdapter) Write(p []byte) (int, error) {
+	a.l.Log(a.level, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// RotatingFile is an io.Writer that appends to a file and rotates it when it
+// grows larger than MaxSize. The rotated files are named path.1, path.2, ...
+// and at most MaxBackups of them are kept.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	fd   *os.File
+	size int64
+}
+
+// OpenRotatingFile opens the file at p for appending.
+func OpenRotatingFile(p string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{Path: p, MaxSize: maxSize, MaxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	fd, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	f.fd = fd
+	f.size = stat.Size()
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.fd.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) rotate() error {
+	f.fd.Close()
+	if f.MaxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", f.Path, f.MaxBackups))
+		for i := f.MaxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.Path, i), fmt.Sprintf("%s.%d", f.Path, i+1))
+		}
+		os.Rename(f.Path, f.Path+".1")
+	} else {
+		os.Remove(f.Path)
+	}
+	return f.open()
+}
+
+// Close closes the underlying file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fd.Close()
+}
@@ -0,0 +1,56 @@
+package launchbar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var b bytes.Buffer
+	l := NewLogger(&b, LevelInfo).With("version", "1.0")
+	l.Debug("hidden")
+	l.Info("fetched url", "url", "http://example.com", "n", 2)
+	l.StdLogger(LevelError).Println("boom")
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines got %q", b.String())
+	}
+	if !strings.HasSuffix(lines[0], ` level=info version=1.0 msg="fetched url" url=http://example.com n=2`) {
+		t.Errorf("bad entry: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], ` level=error version=1.0 msg=boom`) {
+		t.Errorf("bad std entry: %q", lines[1])
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lblog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := path.Join(dir, "error.log")
+	f, err := OpenRotatingFile(p, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		f.Write([]byte("12345678\n"))
+	}
+	f.Close()
+
+	for _, name := range []string{"error.log", "error.log.1", "error.log.2"} {
+		if !exists(path.Join(dir, name)) {
+			t.Errorf("%s does not exist", name)
+		}
+	}
+	if exists(path.Join(dir, "error.log.3")) {
+		t.Errorf("too many backups")
+	}
+}
@@ -0,0 +1,129 @@
+package launchbar
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/codegangsta/inject"
+)
+
+// CallKind tells which kind of func a middleware is wrapping
+type CallKind string
+
+// Call kinds
+const (
+	CallMatch    CallKind = "match"    // a Matcher func of an item
+	CallRender   CallKind = "render"   // a Renderer func of an item
+	CallRun      CallKind = "run"      // a Runner func of an item
+	CallFunc     CallKind = "func"     // a FuncMap func (Item.Run, commands, update)
+	CallComplete CallKind = "complete" // a completion func of a command (see ArgFunc)
+	CallStream   CallKind = "stream"   // a stream producer (see Action.Stream)
+)
+
+// Call describes an invocation of a Matcher, Renderer, Runner or FuncMap func.
+type Call struct {
+	Kind CallKind
+	Name string // the FuncMap name, only for CallFunc
+	Item *Item  // the item the func belongs to, nil for commands
+	Func Func
+
+	Command *Command // the parsed input, only for CallComplete
+}
+
+// Next invokes the next middleware or, at the end of the chain, the func itself.
+type Next func() ([]reflect.Value, error)
+
+// Middleware wraps the invocation of the funcs. It can run code before and
+// after calling next, or short-circuit the call by not calling next and
+// returning its own values (see Values).
+//
+// Example:
+//
+//	func(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+//		if call.Kind == CallFunc && !authorized(c) {
+//			return Values(NewItems().Add(NewItem("Not authorized"))), nil
+//		}
+//		return next()
+//	}
+type Middleware func(c *Context, call *Call, next Next) ([]reflect.Value, error)
+
+// Use adds middleware to the chain. The first added middleware is the outermost.
+func (a *Action) Use(m ...Middleware) *Action {
+	a.middleware = append(a.middleware, m...)
+	return a
+}
+
+// invoke runs fn through the middleware chain. Each call gets its own copy
+// of the Context with Self set to the item of the call and Ctx carrying the
+// deadline of the call (see callTimeout). fn is called with an injector that
+// provides the copy and the context.Context.
+func (a *Action) invoke(call *Call, fn func(inject.Injector) ([]reflect.Value, error)) ([]reflect.Value, error) {
+	c := *a.context
+	if call.Item != nil {
+		c.Self = call.Item
+	}
+	if call.Command != nil {
+		c.Command = call.Command
+	}
+	ctx, cancel := a.context.Ctx, context.CancelFunc(func() {})
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := a.callTimeout(call.Kind)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+	c.Ctx = ctx
+
+	inj := inject.New()
+	inj.SetParent(a.Injector)
+	inj.Map(&c)
+	inj.MapTo(ctx, (*context.Context)(nil))
+
+	h := Next(func() ([]reflect.Value, error) { return fn(inj) })
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		m, next := a.middleware[i], h
+		h = func() ([]reflect.Value, error) { return m(&c, call, next) }
+	}
+	if timeout <= 0 {
+		return h()
+	}
+	return runWithDeadline(ctx, call, timeout, h)
+}
+
+// Values returns vals as the return values of a func, used by a middleware to
+// short-circuit a call.
+func Values(vals ...interface{}) []reflect.Value {
+	out := make([]reflect.Value, len(vals))
+	for i, v := range vals {
+		out[i] = reflect.ValueOf(v)
+	}
+	return out
+}
+
+// RecoverMiddleware turns a panic in a func into an error.
+func RecoverMiddleware(c *Context, call *Call, next Next) (vals []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.Log.Error("panic", "call", call.Kind, "func", call.Name, "panic", r, "stack", string(debug.Stack()))
+			vals, err = nil, fmt.Errorf("%s %s: panic: %v", call.Kind, call.Name, r)
+		}
+	}()
+	return next()
+}
+
+// TimingMiddleware logs the duration of each call at LevelDebug.
+func TimingMiddleware(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+	start := time.Now()
+	vals, err := next()
+	title := ""
+	if call.Item != nil {
+		title = call.Item.item.Title
+	}
+	c.Log.Debug("call", "kind", call.Kind, "func", call.Name, "item", title, "duration", time.Since(start))
+	return vals, err
+}
@@ -0,0 +1,51 @@
+package launchbar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/inject"
+)
+
+func TestMiddleware(t *testing.T) {
+	a := newTestAction(t, nil)
+	v := a.NewView("main")
+	v.NewItem("visible").SetRender(func(c *Context) { c.Self.SetSubtitle("rendered") })
+	v.NewItem("hidden")
+
+	var calls []string
+	a.Use(func(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+		calls = append(calls, "outer "+string(call.Kind)+" "+call.Item.item.Title)
+		return next()
+	}, func(c *Context, call *Call, next Next) ([]reflect.Value, error) {
+		calls = append(calls, "inner "+string(call.Kind)+" "+call.Item.item.Title)
+		if call.Kind == CallMatch && call.Item.item.Title == "hidden" {
+			return Values(false), nil
+		}
+		return next()
+	})
+
+	items := v.Render()
+	if len(items) != 1 || items[0].item.Subtitle != "rendered" {
+		t.Errorf("bad items: %#v", items)
+	}
+	expected := []string{
+		"outer match visible", "inner match visible",
+		"outer render visible", "inner render visible",
+		"outer match hidden", "inner match hidden",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("bad calls:\n%q\nwant:\n%q", calls, expected)
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	a := newTestAction(t, nil).Use(RecoverMiddleware)
+	_, err := a.invoke(&Call{Kind: CallFunc, Name: "boom"}, func(inject.Injector) ([]reflect.Value, error) {
+		panic("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Errorf("expected the panic as an error got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package launchbar
+
+import (
+	"fmt"
+	"time"
+)
+
+// ViewParams are the parameters passed to a view with ShowViewWith.
+type ViewParams map[string]interface{}
+
+// Get returns the value of the parameter or nil.
+func (p ViewParams) Get(key string) interface{} { return p[key] }
+
+// Has returns true if the parameter is set.
+func (p ViewParams) Has(key string) bool { _, ok := p[key]; return ok }
+
+// String returns the value of the parameter as string.
+func (p ViewParams) String(key string) string {
+	if p[key] == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", p[key])
+}
+
+// Int returns the value of the parameter as int, 0 if it's not a number.
+func (p ViewParams) Int(key string) int {
+	switch n := p[key].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// navEntry is an entry of the navigation stack.
+type navEntry struct {
+	View   string     `json:"view"`
+	Params ViewParams `json:"params,omitempty"`
+}
+
+const navKey = "navigation"
+
+// sessionTimeout returns how long the navigation stack is kept between runs
+// of the action, read from the config key sessionTimeout in seconds.
+func (a *Action) sessionTimeout() time.Duration {
+	d := time.Duration(a.Config.GetFloat("sessionTimeout") * float64(time.Second))
+	if d <= 0 {
+		d = time.Minute
+	}
+	return d
+}
+
+// navStack returns the navigation stack. A new session, i.e. no run of the
+// action within the session timeout, starts with the main view.
+func (a *Action) navStack() []navEntry {
+	if a.nav != nil {
+		return a.nav
+	}
+	var stack []navEntry
+	if _, err := a.Cache.Get(navKey, &stack); err != nil || len(stack) == 0 {
+		stack = []navEntry{{View: "main"}}
+	}
+	a.nav = stack
+	return stack
+}
+
+func (a *Action) setNavStack(stack []navEntry) {
+	a.nav = stack
+	a.Cache.Set(navKey, stack, a.sessionTimeout())
+}
+
+// touchSession extends the session of the navigation stack.
+func (a *Action) touchSession() { a.setNavStack(a.navStack()) }
+
+// currentNav returns the top of the navigation stack.
+func (a *Action) currentNav() navEntry {
+	stack := a.navStack()
+	return stack[len(stack)-1]
+}
+
+// CurrentView returns the name of the view on top of the navigation stack.
+func (a *Action) CurrentView() string { return a.currentNav().View }
+
+// Push shows the view with the state and puts it on the navigation stack.
+// The previous view is shown again with Pop or the "← Back" item. The state
+// is available to the view as Context.ViewParams.
+func (a *Action) Push(view string, state map[string]interface{}) {
+	a.setNavStack(append(a.navStack(), navEntry{View: view, Params: state}))
+	a.rerun("")
+}
+
+// ShowViewWith shows the view like ShowView and passes it the params,
+// available to the view as Context.ViewParams. The params are kept with the
+// navigation stack and expire with the session.
+//
+// Example:
+//
+//	c.Action.ShowViewWith("details", map[string]interface{}{"id": 42})
+func (a *Action) ShowViewWith(view string, params map[string]interface{}) {
+	if view == "main" {
+		a.setNavStack([]navEntry{{View: view, Params: params}})
+		a.rerun("")
+		return
+	}
+	a.Push(view, params)
+}
+
+// checkParams returns an error if a parameter required by the view is missing.
+func (v *View) checkParams(params ViewParams) error {
+	for _, key := range v.required {
+		if !params.Has(key) {
+			return fmt.Errorf("view %q: missing parameter %q", v.Name, key)
+		}
+	}
+	return nil
+}
+
+// Pop goes back to the previous view of the navigation stack.
+func (a *Action) Pop() {
+	stack := a.navStack()
+	if len(stack) > 1 {
+		stack = stack[:len(stack)-1]
+	}
+	a.setNavStack(stack)
+	a.rerun("")
+}
+
+// newBackItem creates the "← Back" item that is shown when the navigation
+// stack has more than one view.
+func (a *Action) newBackItem() *Item {
+	i := NewItem(a.T("nav.back"))
+	i.SetActionRunsInBackground(true).
+		SetAction(a.Config.GetString("actionDefaultScript")).
+		SetIcon("at.obdev.LaunchBar:GoBackTemplate").
+		SetOrder(-1).
+		SetMatch(func(c *Context) bool { return len(c.Action.navStack()) > 1 }).
+		SetRun(func(c *Context) { c.Action.Pop() })
+	i.item.ID = len(a.items) + 1
+	a.items = append(a.items, i)
+	return i
+}
@@ -0,0 +1,60 @@
+package launchbar
+
+import "testing"
+
+func TestNavigation(t *testing.T) {
+	a := newTestAction(t, nil)
+	if v := a.CurrentView(); v != "main" {
+		t.Fatalf("new session should start with main got %q", v)
+	}
+
+	a.Push("list", map[string]interface{}{"id": 1})
+	a.Push("details", nil)
+	if calls := a.Client.(*RecordingClient).Calls(); len(calls) != 2 || calls[1].Method != "PerformAction" || calls[1].Args[0] != "Test" {
+		t.Errorf("Push should rerun the action: %#v", calls)
+	}
+	a.nav = nil // force reading the stack from the cache like the next run
+	if v := a.CurrentView(); v != "details" {
+		t.Errorf("expected details got %q", v)
+	}
+	if back := a.newBackItem(); back.match == nil {
+		t.Fatal("back item has no matcher")
+	}
+
+	a.Pop()
+	a.nav = nil
+	if e := a.currentNav(); e.View != "list" || e.Params["id"] != 1.0 {
+		t.Errorf("bad entry after Pop: %#v", e)
+	}
+
+	a.ShowView("main")
+	a.nav = nil
+	if n := len(a.navStack()); n != 1 {
+		t.Errorf("ShowView(main) should reset the stack, got %d entries", n)
+	}
+
+	a.Push("list", nil)
+	a.Cache.Set(navKey, a.navStack(), -1)
+	a.nav = nil
+	if v := a.CurrentView(); v != "main" {
+		t.Errorf("expired session should start with main got %q", v)
+	}
+}
+
+func TestViewParams(t *testing.T) {
+	a := newTestAction(t, nil)
+	details := a.NewView("details").Require("id")
+
+	a.ShowViewWith("details", map[string]interface{}{"id": 42})
+	a.nav = nil
+	params := a.currentNav().Params
+	if params.Int("id") != 42 || params.String("id") != "42" {
+		t.Errorf("bad params: %#v", params)
+	}
+	if err := details.checkParams(params); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := details.checkParams(ViewParams{}); err == nil || err.Error() != `view "details": missing parameter "id"` {
+		t.Errorf("expected a missing parameter error got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package launchbar
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsignedFunc is returned when an item calls a func that is not public
+// without a valid signature.
+var ErrUnsignedFunc = errors.New("unsigned call to a func that is not public")
+
+// loadSecret reads the secret from the file "secret" in dir and creates it if
+// it does not exist.
+func loadSecret(dir string) ([]byte, error) {
+	p := path.Join(dir, "secret")
+	if data, err := ioutil.ReadFile(p); err == nil {
+		if key, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil && len(key) >= 32 {
+			return key, nil
+		}
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(p, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return key, err
+	}
+	return key, nil
+}
+
+// signItem returns the signature of the x-id, x-func, x-funcarg and x-data of
+// the item.
+func signItem(key []byte, i *item) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strconv.Itoa(i.ID)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(i.FuncName))
+	mac.Write([]byte{0})
+	mac.Write([]byte(i.FuncArg))
+	mac.Write([]byte{0})
+	mac.Write(canonicalJSON(i.Data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signItems signs the func calls of the items and their children with the key
+// of the action. The items are signed when they're compiled, right before
+// they're shown.
+func (a *Action) signItems(items []*item) {
+	for _, i := range items {
+		if i.FuncName != "" && len(a.secret) > 0 {
+			i.Sig = signItem(a.secret, i)
+		}
+		a.signItems(i.Children)
+	}
+}
+
+// signJSON signs the func calls of the items in the json output s, e.g. the
+// output of Items.Compile returned by a func as a string. s is returned as is
+// if it's not a json array or has no func calls.
+func (a *Action) signJSON(s string) string {
+	var items []interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&items); err != nil || !a.signJSONItems(items) {
+		return s
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
+
+// signJSONItems signs the decoded items and their children in place and
+// returns true if any of them has a func call. The other fields are kept as
+// they are.
+func (a *Action) signJSONItems(items []interface{}) bool {
+	signed := false
+	for _, v := range items {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if children, ok := m["children"].([]interface{}); ok && a.signJSONItems(children) {
+			signed = true
+		}
+		if _, ok := m["x-func"]; !ok || len(a.secret) == 0 {
+			continue
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			continue
+		}
+		var i item
+		if err := json.Unmarshal(b, &i); err != nil {
+			continue
+		}
+		m["x-sig"] = signItem(a.secret, &i)
+		signed = true
+	}
+	return signed
+}
+
+// verifyItem returns true if the item carries a valid signature.
+func verifyItem(key []byte, i *item) bool {
+	if len(key) == 0 || i.Sig == "" {
+		return false
+	}
+	sig, err := hex.DecodeString(i.Sig)
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(signItem(key, i))
+	return hmac.Equal(sig, expected)
+}
+
+// canonicalJSON encodes v so that the same data always produces the same
+// bytes, no matter how it was decoded: the object keys are sorted and the
+// numbers are kept as written.
+func canonicalJSON(v interface{}) []byte {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return b
+	}
+	b, _ = json.Marshal(generic)
+	if string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+// Public marks the FuncMap funcs that can be called without a signature, e.g.
+// by another action or a URL. Calls to the other funcs are only accepted if
+// the item was created by this action.
+func (a *Action) Public(names ...string) *Action {
+	if a.public == nil {
+		a.public = make(map[string]bool)
+	}
+	for _, name := range names {
+		a.public[name] = true
+	}
+	return a
+}
+
+// signedFuncCall returns the json argument that calls the func with the
+// encoded args.
+func (a *Action) signedFuncCall(name string, args ...interface{}) string {
+	i := NewItem("").Run(name, args...).item
+	a.signItems([]*item{i})
+	b, _ := json.Marshal(i)
+	return string(b)
+}
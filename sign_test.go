@@ -0,0 +1,113 @@
+package launchbar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSignedFuncCall(t *testing.T) {
+	a := newTestAction(t, nil)
+
+	i := a.NewView("main").NewItem("delete").Run("delete", 42)
+	i.item.Data = map[string]interface{}{"n": 1500000}
+	b := compiledItem(t, a, i)
+
+	if in := NewInput(a, []string{string(b)}); !in.hasFunc || in.funcRejected {
+		t.Errorf("signed call should be accepted: %s", b)
+	}
+
+	forged := strings.Replace(string(b), `[42]`, `[43]`, 1)
+	if in := NewInput(a, []string{forged}); in.hasFunc || !in.funcRejected {
+		t.Errorf("forged call should be rejected: %s", forged)
+	}
+
+	unsigned := `{"x-func":"delete","x-funcarg":"[42]"}`
+	if in := NewInput(a, []string{unsigned}); in.hasFunc {
+		t.Errorf("unsigned call should be rejected")
+	}
+
+	a.Public("delete")
+	if in := NewInput(a, []string{unsigned}); !in.hasFunc {
+		t.Errorf("unsigned call to a public func should be accepted")
+	}
+}
+
+// compiledItem returns the json of the item as compiled by the action.
+func compiledItem(t *testing.T, a *Action, i *Item) []byte {
+	var out []json.RawMessage
+	if err := json.Unmarshal([]byte(a.compileOutput(Values(NewItems().Add(i)))), &out); err != nil || len(out) != 1 {
+		t.Fatalf("bad output: %v", err)
+	}
+	return out[0]
+}
+
+func TestSignPerAction(t *testing.T) {
+	a := newTestAction(t, nil)
+	b := newTestAction(t, nil)
+
+	i := NewItem("delete").Run("delete", 42)
+	if m, _ := json.Marshal(i.item); strings.Contains(string(m), "x-sig") || i.item.Sig != "" {
+		t.Errorf("json.Marshal signed the item: %s", m)
+	}
+	signed := compiledItem(t, a, i)
+	if in := NewInput(a, []string{string(signed)}); !in.hasFunc {
+		t.Errorf("the call should be accepted by the action that signed it")
+	}
+	if in := NewInput(b, []string{string(signed)}); in.hasFunc || !in.funcRejected {
+		t.Errorf("the call should be rejected by another action")
+	}
+
+	parent := NewItem("parent").SetChildren(NewItems().Add(NewItem("child").Run("delete", 1)))
+	a.compileOutput(Values(NewItems().Add(parent)))
+	if !verifyItem(a.secret, parent.item.Children[0]) {
+		t.Errorf("the children are not signed")
+	}
+}
+
+func TestLoadSecret(t *testing.T) {
+	a := newTestAction(t, nil)
+	k1, err := loadSecret(a.SupportPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, _ := loadSecret(a.SupportPath())
+	if string(k1) != string(k2) || len(k1) != 32 {
+		t.Errorf("secret is not persisted")
+	}
+}
+
+func TestSignStringOutput(t *testing.T) {
+	deleted := 0
+	a := newTestAction(t, []string{"list"}, FuncMap{
+		"list": func() string {
+			return NewItems().Add(NewItem("milk").Run("delete", 42).SetChildren(NewItems().Add(NewItem("child").Run("delete", 1)))).Compile()
+		},
+		"delete": func(id int) { deleted = id },
+	})
+	a.NewView("main")
+	a.NewCommand("list").SetFunc("list")
+
+	var out []map[string]interface{}
+	if err := json.Unmarshal([]byte(a.Run()), &out); err != nil || len(out) != 1 {
+		t.Fatalf("bad output: %v", err)
+	}
+	if out[0]["title"] != "milk" || out[0]["children"] == nil {
+		t.Errorf("the output changed: %v", out)
+	}
+	selected, _ := json.Marshal(out[0])
+	a.Input = NewInput(a, []string{string(selected)})
+	a.context.Input = a.Input
+	a.Run()
+	if deleted != 42 {
+		t.Errorf("the selected item's func was not run: %s", selected)
+	}
+
+	child, _ := json.Marshal(out[0]["children"].([]interface{})[0])
+	if in := NewInput(a, []string{string(child)}); !in.hasFunc {
+		t.Errorf("the children are not signed: %s", child)
+	}
+	if s := "not json"; a.signJSON(s) != s {
+		t.Errorf("signJSON changed %q", s)
+	}
+}
@@ -0,0 +1,158 @@
+package launchbar
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/codegangsta/inject"
+)
+
+// streamFunc is the reserved x-func that runs a stream producer in a detached
+// process, see Action.Stream.
+const streamFunc = "x-stream"
+
+// streamState is the partial result of a stream kept in the cache.
+type streamState struct {
+	Query   string    `json:"query"`
+	Items   []*item   `json:"items"`
+	Done    bool      `json:"done"`
+	Started time.Time `json:"started"`
+}
+
+func streamKey(name string) string { return "stream-" + name }
+
+// Stream returns the items produced so far by the FuncMap func name for the
+// query. The first call for a query starts the producer in a detached process
+// and every batch it writes re-triggers the action, so LaunchBar shows the
+// results as they are ready. While the producer is running a
+// "Searching… (N results)" item is shown first.
+//
+// The producer is invoked with the StreamWriter and the query:
+//
+//	FuncMap{"search": func(c *Context, w *StreamWriter, query string) {
+//		for _, r := range search(query) {
+//			w.Write(NewItem(r.Title))
+//		}
+//	}}
+//
+// and used from a Renderer, Runner or FuncMap func:
+//
+//	func(c *Context) *Items { return c.Action.Stream("search", c.Input.String()) }
+//
+// The producer is not cut off by runTimeout, set streamTimeout to limit it. A
+// producer that is not done after streamTimeout (or 10 minutes without it) is
+// restarted by the next call.
+func (a *Action) Stream(name, query string) *Items {
+	var state streamState
+	_, err := a.Cache.Get(streamKey(name), &state)
+	maxAge := a.callTimeout(CallStream)
+	if maxAge <= 0 {
+		maxAge = 10 * time.Minute
+	}
+	stale := !state.Done && time.Since(state.Started) > maxAge+10*time.Second
+	if err == ErrCacheDoesNotExists || state.Query != query || stale {
+		state = streamState{Query: query, Started: time.Now()}
+		a.Cache.Set(streamKey(name), state, time.Hour)
+		cmd := exec.Command(os.Args[0], a.signedFuncCall(streamFunc, name, query))
+		if err := cmd.Start(); err != nil {
+			a.Log.Error("cannot start the stream", "stream", name, "error", err)
+			return NewItems().Add(a.errorItem(err))
+		}
+	}
+
+	items := NewItems()
+	if !state.Done {
+		items.Add(NewItem(a.T("stream.searching", len(state.Items))).
+			SetSubtitle(query).
+			SetActionArgument(query))
+	}
+	items.setItems(state.Items)
+	return items
+}
+
+// StreamWriter writes the batches of items of a stream producer.
+type StreamWriter struct {
+	a         *Action
+	name      string
+	state     streamState
+	lastRerun time.Time
+	mu        sync.Mutex
+}
+
+// Query returns the query the producer was started for.
+func (w *StreamWriter) Query() string { return w.state.Query }
+
+// Len returns the number of items written so far.
+func (w *StreamWriter) Len() int { w.mu.Lock(); defer w.mu.Unlock(); return len(w.state.Items) }
+
+// Write appends a batch of items to the result and re-triggers the action
+// (at most twice a second) to show them.
+func (w *StreamWriter) Write(items ...*Item) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, i := range items {
+		w.state.Items = append(w.state.Items, i.item)
+	}
+	if !w.current() {
+		return
+	}
+	w.a.Cache.Set(streamKey(w.name), w.state, time.Hour)
+	if time.Since(w.lastRerun) > 500*time.Millisecond {
+		w.lastRerun = time.Now()
+		w.a.rerun(w.state.Query)
+	}
+}
+
+// Close marks the stream as done and shows the final result.
+func (w *StreamWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state.Done = true
+	if !w.current() {
+		return
+	}
+	w.a.Cache.Set(streamKey(w.name), w.state, time.Hour)
+	w.a.rerun(w.state.Query)
+}
+
+// current returns false if a producer for another query has been started
+// since, the result of this one is not wanted anymore.
+func (w *StreamWriter) current() bool {
+	var state streamState
+	w.a.Cache.Get(streamKey(w.name), &state)
+	return state.Query == w.state.Query && state.Started.Equal(w.state.Started)
+}
+
+// runStream runs the producer of a stream started by Stream.
+func (a *Action) runStream(in *Input) {
+	args := decodeFuncArgs(in.Item.item.FuncArg)
+	var name string
+	if err := in.DecodeFuncArg(0, &name); err != nil {
+		a.Log.Error("bad stream call", "error", err)
+		return
+	}
+	fn, ok := (*a.funcs)[name]
+	if !ok {
+		a.Log.Error("unknown stream func", "func", name)
+		return
+	}
+
+	w := &StreamWriter{a: a, name: name}
+	if _, err := a.Cache.Get(streamKey(name), &w.state); err != nil && err != ErrCacheIsExpired {
+		a.Log.Error("no stream state", "func", name, "error", err)
+		return
+	}
+	defer w.Close()
+
+	_, err := a.invoke(&Call{Kind: CallStream, Name: name, Func: fn}, func(inj inject.Injector) ([]reflect.Value, error) {
+		inj.Map(w)
+		return invokeFunc(inj, name, fn, args[1:])
+	})
+	if err != nil {
+		a.Log.Error("stream func failed", "func", name, "error", err)
+		w.Write(a.errorItem(err))
+	}
+}
@@ -0,0 +1,74 @@
+package launchbar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	a := newTestAction(t, nil)
+
+	started := time.Now()
+	a.Cache.Set(streamKey("search"), streamState{Query: "go", Started: started}, time.Hour)
+
+	items := *a.Stream("search", "go")
+	if len(items) != 1 || items[0].item.Title != "Searching… (0 results)" {
+		t.Fatalf("expected the placeholder item got %#v", items)
+	}
+
+	w := &StreamWriter{a: a, name: "search", state: streamState{Query: "go", Started: started}}
+	w.Write(NewItem("a"), NewItem("b"))
+	items = *a.Stream("search", "go")
+	if len(items) != 3 || items[0].item.Title != "Searching… (2 results)" || items[2].item.Title != "b" {
+		t.Fatalf("bad partial result: %#v", items)
+	}
+
+	w.Close()
+	items = *a.Stream("search", "go")
+	if len(items) != 2 || items[0].item.Title != "a" {
+		t.Fatalf("bad final result: %#v", items)
+	}
+
+	// a writer of an older query must not overwrite the result
+	old := &StreamWriter{a: a, name: "search", state: streamState{Query: "old", Started: started}}
+	old.Write(NewItem("c"))
+	if items = *a.Stream("search", "go"); len(items) != 2 {
+		t.Errorf("result overwritten by an old producer: %#v", items)
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Config.data["runTimeout"] = 0.01
+	*a.funcs = FuncMap{
+		"search": func(ctx context.Context, w *StreamWriter, query string) {
+			// outlives runTimeout, producers are only limited by streamTimeout
+			time.Sleep(50 * time.Millisecond)
+			if ctx.Err() != nil {
+				return // timed out, the test is over
+			}
+			w.Write(NewItem(query + " 1"))
+			w.Write(NewItem(query + " 2"))
+		},
+	}
+	a.Cache.Set(streamKey("search"), streamState{Query: "go", Started: time.Now()}, time.Hour)
+
+	a.runStream(NewInput(a, []string{a.signedFuncCall(streamFunc, "search", "go")}))
+	items := *a.Stream("search", "go")
+	if len(items) != 2 || items[0].item.Title != "go 1" || items[1].item.Title != "go 2" {
+		t.Fatalf("bad result: %#v", items)
+	}
+	if calls := a.Client.(*RecordingClient).Calls(); len(calls) == 0 || calls[len(calls)-1].Method != "PerformAction" {
+		t.Errorf("the action was not rerun: %v", calls)
+	}
+
+	a.Config.data["streamTimeout"] = 0.01
+	a.Cache.Set(streamKey("search"), streamState{Query: "go", Started: time.Now()}, time.Hour)
+	a.runStream(NewInput(a, []string{a.signedFuncCall(streamFunc, "search", "go")}))
+	items = *a.Stream("search", "go")
+	if len(items) != 1 || !strings.Contains(items[0].item.Title, "timed out") {
+		t.Errorf("expected the timeout error item got %#v", items)
+	}
+}
@@ -0,0 +1,250 @@
+package launchbar
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the funcs available to the item templates, see
+// Item.SetTitleTemplate.
+//
+//	truncate n s     s cut to n characters with "…"
+//	bytes n          n bytes as 1.5 KB, 3.2 MB, …
+//	ago t            time.Time t as "5 minutes ago", "in 2 hours", …
+//	pluralize n word "1 result", "2 results"
+//
+// ago and pluralize are localized with the strings of the action (see
+// Action.T): ago with the time.* keys, pluralize with the plural message of
+// the key word if there is one.
+var TemplateFuncs = template.FuncMap{
+	"truncate":  truncate,
+	"bytes":     humanizeBytes,
+	"ago":       englishStrings.humanizeTime,
+	"pluralize": func(n interface{}, word string) string { return englishStrings.pluralize(toInt(n), word) },
+}
+
+// templateFuncs returns the TemplateFuncs that are localized by l.
+func (l *localizer) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"ago":       l.humanizeTime,
+		"pluralize": func(n interface{}, word string) string { return l.pluralize(toInt(n), word) },
+	}
+}
+
+// templateData is the data of the item templates.
+type templateData struct {
+	Input  *Input
+	Config *Config
+	Cache  templateCache
+	Data   map[string]interface{} // the data of the item, see Input.Data
+	Params ViewParams
+}
+
+// templateCache gives the templates access to the cached values:
+//
+//	{{.Cache.Get "weather"}}
+type templateCache struct{ c *Cache }
+
+// Get returns the cached value or nil.
+func (t templateCache) Get(key string) interface{} {
+	var v interface{}
+	if _, err := t.c.Get(key, &v); err != nil && err != ErrCacheIsExpired {
+		return nil
+	}
+	return v
+}
+
+// itemTemplate is a template of the title or subtitle of an item, err is the
+// parse error shown when the item is rendered. The template is bound to the
+// localized funcs of the action on the first execution.
+type itemTemplate struct {
+	tmpl *template.Template
+	err  error
+
+	once  sync.Once
+	bound *template.Template
+}
+
+func newItemTemplate(name, text string) *itemTemplate {
+	t, err := template.New(name).Funcs(TemplateFuncs).Parse(text)
+	return &itemTemplate{tmpl: t, err: err}
+}
+
+func (t *itemTemplate) execute(a *Action, data *templateData) (string, error) {
+	t.once.Do(func() {
+		if t.err != nil {
+			return
+		}
+		if t.bound, t.err = t.tmpl.Clone(); t.err == nil {
+			t.bound.Funcs(a.localizer().templateFuncs())
+		}
+	})
+	if t.err != nil {
+		return "", t.err
+	}
+	var b bytes.Buffer
+	if err := t.bound.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// SetTitleTemplate sets the title to the text/template executed each time the
+// view is rendered, after the Renderer func. The template has .Input, .Config,
+// .Cache, .Data (the item data) and .Params (see ShowViewWith) and the
+// TemplateFuncs. If the template fails the error is shown in the subtitle.
+//
+// Example:
+//
+//	i.SetTitleTemplate(`Search "{{.Input.String | truncate 30}}"`)
+func (i *Item) SetTitleTemplate(text string) *Item {
+	i.titleTmpl = newItemTemplate("title", text)
+	return i
+}
+
+// SetSubtitleTemplate sets the subtitle to the template, see SetTitleTemplate.
+//
+// Example:
+//
+//	i.SetSubtitleTemplate(`{{.Data.size | bytes}}, modified {{.Data.modified | ago}}`)
+func (i *Item) SetSubtitleTemplate(text string) *Item {
+	i.subtitleTmpl = newItemTemplate("subtitle", text)
+	return i
+}
+
+// executeTemplates sets the title and subtitle of the item from its templates.
+func (i *Item) executeTemplates(c *Context) {
+	if i.titleTmpl == nil && i.subtitleTmpl == nil {
+		return
+	}
+	data := &templateData{
+		Input:  c.Input,
+		Config: c.Config,
+		Cache:  templateCache{c.Cache},
+		Data:   i.item.Data,
+		Params: c.ViewParams,
+	}
+	for _, t := range []struct {
+		tmpl *itemTemplate
+		set  func(string) *Item
+	}{{i.titleTmpl, i.SetTitle}, {i.subtitleTmpl, i.SetSubtitle}} {
+		if t.tmpl == nil {
+			continue
+		}
+		s, err := t.tmpl.execute(c.Action, data)
+		if err != nil {
+			c.Log.Warn("template failed", "item", i.item.Title, "error", err)
+			i.SetSubtitle(err.Error())
+			return
+		}
+		t.set(s)
+	}
+}
+
+// truncate cuts s to n characters, the last one replaced with "…".
+func truncate(n int, s string) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// humanizeBytes returns the size n in bytes as 512 B, 1.5 KB, 3.2 MB, …
+func humanizeBytes(n interface{}) string {
+	f := toFloat(n)
+	if math.Abs(f) < 1024 {
+		return fmt.Sprintf("%d B", int64(f))
+	}
+	units := []string{"KB", "MB", "GB", "TB", "PB"}
+	u := -1
+	for math.Abs(f) >= 1024 && u < len(units)-1 {
+		f /= 1024
+		u++
+	}
+	return fmt.Sprintf("%.1f %s", f, units[u])
+}
+
+// humanizeTime returns t relative to now, e.g. "5 minutes ago" or "in 2 hours".
+// t is a time.Time or a string in RFC 3339 format.
+func (l *localizer) humanizeTime(t interface{}) string {
+	var tm time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tm = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		tm = *v
+	case string:
+		var err error
+		if tm, err = time.Parse(time.RFC3339, v); err != nil {
+			return v
+		}
+	default:
+		return fmt.Sprint(t)
+	}
+	return l.relativeTime(tm, time.Now())
+}
+
+func (l *localizer) relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var s string
+	switch {
+	case d < time.Minute:
+		return l.t("time.justNow")
+	case d < time.Hour:
+		s = l.t("time.minutes", int(d/time.Minute))
+	case d < 24*time.Hour:
+		s = l.t("time.hours", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		s = l.t("time.days", int(d/(24*time.Hour)))
+	case d < 365*24*time.Hour:
+		s = l.t("time.months", int(d/(30*24*time.Hour)))
+	default:
+		s = l.t("time.years", int(d/(365*24*time.Hour)))
+	}
+	if future {
+		return l.t("time.in", s)
+	}
+	return l.t("time.ago", s)
+}
+
+// toFloat converts a number of any type, e.g. float64 from json, to float64.
+func toFloat(n interface{}) float64 {
+	v := reflect.ValueOf(n)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return 0
+}
+
+func toInt(n interface{}) int { return int(toFloat(n)) }
+
+// pluralize returns n with the plural form of the message of the key word, or
+// with the singular or English plural (word + "s") form of word if there's no
+// plural message.
+func (l *localizer) pluralize(n int, word string) string {
+	if m, lang, ok := l.lookup(word); ok && m.plural != nil {
+		return m.format(lang, []interface{}{n})
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
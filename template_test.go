@@ -0,0 +1,98 @@
+package launchbar
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemTemplates(t *testing.T) {
+	a := newTestAction(t, []string{"a long query string"})
+	a.Cache.Set("count", 3, time.Minute)
+	v := a.NewView("main")
+	v.NewItem("").
+		SetTitleTemplate(`Search "{{.Input.String | truncate 8}}"`).
+		SetSubtitleTemplate(`{{.Data.size | bytes}}, {{pluralize (.Cache.Get "count") "hit"}}, debug {{.Config.GetBool "debug"}}`).
+		SetRender(func(c *Context) { c.Self.item.Data = map[string]interface{}{"size": 1536.0} })
+	v.NewItem("broken").SetSubtitleTemplate(`{{.Nope}}`)
+	v.NewItem("unparsable").SetTitleTemplate(`{{`)
+
+	items := v.Render()
+	if s := items[0].item.Title; s != `Search "a long …"` {
+		t.Errorf("title = %q", s)
+	}
+	if s := items[0].item.Subtitle; s != "1.5 KB, 3 hits, debug false" {
+		t.Errorf("subtitle = %q", s)
+	}
+	if s := items[1].item.Subtitle; !strings.Contains(s, "Nope") {
+		t.Errorf("execute error not shown: %q", s)
+	}
+	if s := items[2].item; s.Title != "unparsable" || !strings.Contains(s.Subtitle, "unclosed action") {
+		t.Errorf("parse error not shown: %q %q", s.Title, s.Subtitle)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	now := time.Date(2015, 6, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		got, want string
+	}{
+		{truncate(5, "héllo world"), "héll…"},
+		{truncate(5, "héllo"), "héllo"},
+		{humanizeBytes(512), "512 B"},
+		{humanizeBytes(int64(1) << 20), "1.0 MB"},
+		{humanizeBytes(uint(3) << 30 / 2), "1.5 GB"},
+		{englishStrings.relativeTime(now.Add(-30*time.Second), now), "just now"},
+		{englishStrings.relativeTime(now.Add(-5*time.Minute), now), "5 minutes ago"},
+		{englishStrings.relativeTime(now.Add(time.Hour), now), "in 1 hour"},
+		{englishStrings.relativeTime(now.Add(-72*time.Hour), now), "3 days ago"},
+		{englishStrings.relativeTime(now.AddDate(-2, 0, 0), now), "2 years ago"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("got %q want %q", test.got, test.want)
+		}
+	}
+}
+
+func TestTemplateFuncsLocalized(t *testing.T) {
+	t.Setenv("LC_ALL", "ru_RU.UTF-8")
+	a := newTestAction(t, nil)
+	res := path.Join(a.ActionPath(), "Contents", "Resources", "ru.lproj")
+	os.MkdirAll(res, 0755)
+	catalog := `{
+		"time.ago": "%s назад",
+		"time.justNow": "только что",
+		"time.minutes": {"one": "%d минуту", "few": "%d минуты", "many": "%d минут"},
+		"hit": {"one": "%d совпадение", "few": "%d совпадения", "many": "%d совпадений"}
+	}`
+	if err := ioutil.WriteFile(path.Join(res, "Localizable.json"), []byte(catalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := a.NewView("main")
+	v.NewItem("").
+		SetTitleTemplate(`{{.Data.seen | ago}}, {{pluralize 2 "hit"}}`).
+		SetSubtitleTemplate(`{{pluralize 5 "file"}}`).
+		SetRender(func(c *Context) {
+			c.Self.item.Data = map[string]interface{}{"seen": time.Now().Add(-3 * time.Minute)}
+		})
+	v.NewItem("").SetTitleTemplate(`{{.Data.seen | ago}}`).SetRender(func(c *Context) {
+		c.Self.item.Data = map[string]interface{}{"seen": time.Now().Add(-2 * time.Hour)}
+	})
+
+	items := v.Render()
+	if s := items[0].item.Title; s != "3 минуты назад, 2 совпадения" {
+		t.Errorf("title = %q", s)
+	}
+	if s := items[0].item.Subtitle; s != "5 files" {
+		t.Errorf("subtitle = %q", s)
+	}
+	// the missing keys fall back to the English strings
+	if s := items[1].item.Title; s != "2 hours назад" {
+		t.Errorf("title = %q", s)
+	}
+}
@@ -0,0 +1,83 @@
+package launchbar
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// TimeoutError is returned when a func misses its deadline.
+//
+// The deadlines are read from the config in seconds, 0 disables them:
+//
+//	renderTimeout  Matcher, Renderer and completion funcs (default 2)
+//	runTimeout     Runner and FuncMap funcs (default 30)
+//	streamTimeout  stream producers (default 0, they run until they're done)
+//
+// Funcs can take a context.Context (or use Context.Ctx) to stop their work
+// when the deadline is exceeded.
+type TimeoutError struct {
+	Call    *Call
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	name := e.Call.Name
+	if name == "" && e.Call.Item != nil {
+		name = e.Call.Item.item.Title
+	}
+	return fmt.Sprintf("%s %q timed out after %v", e.Call.Kind, name, e.Timeout)
+}
+
+// IsTimeout returns true if err is a *TimeoutError.
+func IsTimeout(err error) bool {
+	_, ok := err.(*TimeoutError)
+	return ok
+}
+
+// callTimeout returns the deadline for the kind of call.
+func (a *Action) callTimeout(kind CallKind) time.Duration {
+	key := "runTimeout"
+	switch kind {
+	case CallMatch, CallRender, CallComplete:
+		key = "renderTimeout"
+	case CallStream:
+		key = "streamTimeout"
+	}
+	return time.Duration(a.Config.GetFloat(key) * float64(time.Second))
+}
+
+// runWithDeadline calls h and waits for it until ctx is done. A func that
+// misses the deadline keeps running in the background but its result is
+// discarded. A panic in h is re-raised in the calling goroutine.
+func runWithDeadline(ctx context.Context, call *Call, timeout time.Duration, h Next) ([]reflect.Value, error) {
+	type result struct {
+		vals  []reflect.Value
+		err   error
+		panic interface{}
+	}
+	ch := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- result{panic: r}
+			}
+		}()
+		vals, err := h()
+		ch <- result{vals: vals, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		if r.panic != nil {
+			panic(r.panic)
+		}
+		return r.vals, r.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{call, timeout}
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package launchbar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTimeout(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Config.data["renderTimeout"] = 0.05
+	cancelled := make(chan bool, 1)
+
+	v := a.NewView("main")
+	v.NewItem("slow").SetRender(func(ctx context.Context, c *Context) {
+		select {
+		case <-ctx.Done():
+			cancelled <- true
+		case <-time.After(time.Second):
+			c.Self.SetSubtitle("done")
+		}
+	})
+	v.NewItem("fast").SetRender(func(c *Context) { c.Self.SetSubtitle("done") })
+
+	items := v.Render()
+	if len(items) != 2 || items[0].item.Subtitle != "loading…" || items[1].item.Subtitle != "done" {
+		t.Errorf("bad items: %q %q", items[0].item.Subtitle, items[1].item.Subtitle)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("the renderer was not cancelled")
+	}
+
+	a.Config.data["timeoutMode"] = "hide"
+	if items := v.Render(); len(items) != 1 {
+		t.Errorf("expected the slow item to be hidden got %d items", len(items))
+	}
+}
+
+func TestRenderTimeoutDiscardsLateWrites(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Config.data["renderTimeout"] = 0.02
+	done := make(chan bool)
+
+	v := a.NewView("main")
+	v.NewItem("stubborn").SetRender(func(c *Context) {
+		defer close(done)
+		// ignores the deadline and keeps writing to the item
+		for start := time.Now(); time.Since(start) < 100*time.Millisecond; {
+			c.Self.SetTitle("late").SetSubtitle("late")
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	out := v.Compile()
+	<-done
+	if !strings.Contains(out, `"subtitle":"loading…"`) || strings.Contains(out, "late") {
+		t.Errorf("bad output: %s", out)
+	}
+	if i := v.Items[0].item; i.Title != "stubborn" || i.Subtitle != "loading…" {
+		t.Errorf("the late writes changed the item: %q %q", i.Title, i.Subtitle)
+	}
+}
@@ -3,16 +3,16 @@ package launchbar
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-
 	"time"
 
 	"github.com/DHowett/go-plist"
 )
 
 func update(c *Context) string {
-	updateLink := c.Action.info["LBDescription"].(map[string]interface{})["LBUpdate"].(string)
+	updateLink := c.Action.info.LBDescription.LBUpdate
+	if updateLink == "" {
+		return die("no updateLink", "LBDescription.LBUpdate is not set in the Info.plist")
+	}
 	var updateStartTime time.Time
 	if _, err := c.Cache.Get("updateStartTime", &updateStartTime); err == nil {
 		return die("update in progress", fmt.Sprintf("update check in progress (started %v ago)", time.Now().Sub(updateStartTime)))
@@ -22,42 +22,13 @@ func update(c *Context) string {
 		c.Cache.Delete("updateStartTime")
 	}()
 
-	var data, updatePlist []byte
-	var etag, updateETag string
-	c.Cache.Get("updateETag", &etag)
-	if etag != "" {
-		resp, err := http.Head(updateLink)
-		if err != nil {
-			return die("cannot get updateLink", fmt.Sprintf("%v", err))
-		}
-		updateETag = resp.Header.Get("etag")
-		if etag == updateETag {
-			c.Cache.Get("updatePlist", &updatePlist)
-			if len(updatePlist) != 0 {
-				data = updatePlist
-			}
-		}
-	}
-
-	if len(data) == 0 {
-		resp, err := http.Get(updateLink)
-		if err != nil {
-			return die("cannot get updateLink", fmt.Sprintf("%v", err))
-		}
-		if resp.StatusCode >= 400 {
-			return die("cannot get updateLink", fmt.Sprintf("%v", resp.Status))
-		}
-		defer resp.Body.Close()
-		data, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return die("cannot get updateLink", fmt.Sprintf("%v", err))
-		}
-		c.Cache.Set("updateETag", resp.Header.Get("etag"), 0)
-		c.Cache.Set("updatePlist", data, 0)
+	data, err := c.Cache.FetchURL(updateLink, 0)
+	if err != nil && err != ErrCacheIsExpired {
+		return die("cannot get updateLink", fmt.Sprintf("%v", err))
 	}
 
 	var v map[string]interface{}
-	_, err := plist.Unmarshal(data, &v)
+	_, err = plist.Unmarshal(data, &v)
 	if err != nil {
 		return die("cannot parse updateLink", fmt.Sprintf("Error: %v\nData: %s", err, string(data)))
 	}
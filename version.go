@@ -1,6 +1,8 @@
 package launchbar
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -8,10 +10,15 @@ import (
 // Version represents a version string (e.g. 1.0, 1.0, 1.0.0)
 type Version string
 
+var reVersion = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
 func parseVersion(s string) (major, minor, patch int) {
 	components := [3]int{0, 0, 0}
 	parts := strings.Split(s, ".")
 	for i, part := range parts {
+		if i >= len(components) {
+			break
+		}
 		parti, _ := strconv.Atoi(part)
 		components[i] = parti
 	}
@@ -56,3 +63,25 @@ func (v Version) Less(w Version) bool {
 func (v Version) Equal(w Version) bool {
 	return v.Cmp(w) == 0
 }
+
+// Valid returns true if v has one to three numeric components, e.g. 1, 1.2,
+// 1.2.3.
+func (v Version) Valid() bool { return reVersion.MatchString(string(v)) }
+
+// Bump returns the next version of v. part is "major" (1.2.3 -> 2.0),
+// "minor" (1.2.3 -> 1.3) or "patch" (1.2.3 -> 1.2.4).
+func (v Version) Bump(part string) (Version, error) {
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid version %q", v)
+	}
+	major, minor, patch := parseVersion(string(v))
+	switch part {
+	case "major":
+		return Version(fmt.Sprintf("%d.0", major+1)), nil
+	case "minor":
+		return Version(fmt.Sprintf("%d.%d", major, minor+1)), nil
+	case "patch":
+		return Version(fmt.Sprintf("%d.%d.%d", major, minor, patch+1)), nil
+	}
+	return v, fmt.Errorf("unknown version part %q", part)
+}
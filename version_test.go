@@ -115,3 +115,32 @@ func TestVersionLess(t *testing.T) {
 		}
 	}
 }
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		v, part, out string
+	}{
+		{"1.2.3", "major", "2.0"},
+		{"1.2.3", "minor", "1.3"},
+		{"1.2.3", "patch", "1.2.4"},
+		{"1", "patch", "1.0.1"},
+		{"0.9", "minor", "0.10"},
+	}
+	for _, test := range tests {
+		out, err := Version(test.v).Bump(test.part)
+		if err != nil || string(out) != test.out {
+			t.Errorf("Version(%q).Bump(%q) = %q, %v want %q", test.v, test.part, out, err, test.out)
+		}
+		if !Version(test.v).Less(out) {
+			t.Errorf("%q is not less than %q", test.v, out)
+		}
+	}
+	for _, v := range []string{"", "1.2.3.4", "v1", "1..2"} {
+		if _, err := Version(v).Bump("patch"); err == nil {
+			t.Errorf("Version(%q).Bump did not fail", v)
+		}
+	}
+	if _, err := Version("1").Bump("build"); err == nil {
+		t.Errorf("Bump(build) did not fail")
+	}
+}
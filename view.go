@@ -3,7 +3,11 @@ package launchbar
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
+	"sync"
+
+	"github.com/codegangsta/inject"
 )
 
 // View represents collection of Items in LaunchBar
@@ -11,6 +15,10 @@ type View struct {
 	Action *Action
 	Name   string
 	Items  Items
+
+	concurrency int
+	required    []string
+	completions bool // the view shows the text input, add the completions
 }
 
 // NewItem creates an always matching Item that runs in background and adds it to the view.
@@ -39,42 +47,122 @@ func (v *View) AddItem(item *Item) *View {
 }
 
 // Render executes each Item Render, Match functions and returns them.
+//
+// The funcs of each item get their own Context copy with Self set to the item.
+// If the view is concurrent (see SetConcurrency) the items are rendered by a
+// pool of workers, the output order is still determined by the item's Order.
 func (v *View) Render() Items {
-	if len(v.Items) == 0 {
+	if len(v.Items) == 0 && len(v.Action.commands) == 0 {
 		return Items(nil)
 	}
 
-	items := &Items{}
-	for _, item := range v.Items {
-		v.Action.context.Self = item
-		if item.match != nil {
-			vals, err := v.Action.Invoke(item.match)
-			if err != nil {
-				v.Action.Logger.Fatalln(err)
-				panic(err)
-			}
-			if len(vals) > 0 {
-				if !vals[0].Bool() {
-					continue
+	include := make([]bool, len(v.Items))
+	errs := make([]error, len(v.Items))
+	if v.concurrency > 1 && len(v.Items) > 1 {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < v.concurrency && w < len(v.Items); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := range jobs {
+					include[n], errs[n] = v.renderItem(v.Items[n])
 				}
-			}
+			}()
 		}
-		if item.render != nil {
-			_, err := v.Action.Invoke(item.render)
-			if err != nil {
-				v.Action.Logger.Fatalln(err)
-				panic(err)
-			}
+		for n := range v.Items {
+			jobs <- n
+		}
+		close(jobs)
+		wg.Wait()
+	} else {
+		for n, item := range v.Items {
+			include[n], errs[n] = v.renderItem(item)
 		}
-		item.item.Arg = v.Action.Input.String()
-		items.Add(item)
 	}
-	sort.Sort(itemsByOrder(*items))
+
+	items := &Items{}
+	for n, item := range v.Items {
+		if errs[n] != nil {
+			v.Action.Logger.Fatalln(errs[n])
+			panic(errs[n])
+		}
+		if include[n] {
+			items.Add(item)
+		}
+	}
+	sort.Stable(itemsByOrder(*items))
+
+	if v.completions {
+		items.Add(*v.Action.Completions(v.Action.Input.String())...)
+	}
 
 	return *items
 
 }
 
+// renderItem runs the Matcher and Renderer funcs and the templates of the item
+// and returns true if the item should be shown.
+//
+// The funcs work on a copy of the item that is merged into it when they're
+// done, the changes made through a captured *Item are kept as well. A func
+// that misses its deadline keeps running in the background, the changes it
+// made through c.Self are thrown away.
+func (v *View) renderItem(item *Item) (bool, error) {
+	orig, base, item := item, item.clone(), item.clone()
+	if item.match != nil {
+		vals, err := v.Action.invoke(&Call{Kind: CallMatch, Item: item, Func: item.match}, func(inj inject.Injector) ([]reflect.Value, error) {
+			return inj.Invoke(item.match)
+		})
+		if IsTimeout(err) {
+			v.Action.Log.Warn("matcher timed out", "error", err)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(vals) > 0 {
+			if !vals[0].Bool() {
+				return false, nil
+			}
+		}
+	}
+	if item.render != nil {
+		_, err := v.Action.invoke(&Call{Kind: CallRender, Item: item, Func: item.render}, func(inj inject.Injector) ([]reflect.Value, error) {
+			return inj.Invoke(item.render)
+		})
+		if IsTimeout(err) {
+			v.Action.Log.Warn("renderer timed out", "error", err)
+			if v.Action.Config.GetString("timeoutMode") == "hide" {
+				return false, nil
+			}
+			item = base.clone()
+			item.SetSubtitle(v.Action.T("render.loading"))
+			err = nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	orig.merge(base, item)
+	item = orig
+	c := *v.Action.context
+	c.Self = item
+	item.executeTemplates(&c)
+	item.item.Arg = v.Action.Input.String()
+	return true, nil
+}
+
+// Require declares the parameters (see ShowViewWith) the view needs. If one
+// is missing an error item is shown instead of the view.
+func (v *View) Require(params ...string) *View { v.required = append(v.required, params...); return v }
+
+// SetConcurrency renders the items of the view with n workers. Use it when the
+// Matcher and Renderer funcs are slow (e.g. network backed), the funcs must
+// not share state without synchronization. n <= 1 renders the items one after
+// another, which is the default.
+func (v *View) SetConcurrency(n int) *View { v.concurrency = n; return v }
+
 // Compile renders and output the view.Items as a json string.
 func (v *View) Compile() string {
 	items := v.Render()
@@ -82,6 +170,7 @@ func (v *View) Compile() string {
 	if items == nil {
 		return ""
 	}
+	v.Action.signItems(items.getItems())
 
 	b, err := json.Marshal(items.getItems())
 	if err != nil {
@@ -95,5 +184,5 @@ func (v *View) Join(w *View) *View {
 	if w == nil {
 		return v
 	}
-	return &View{v.Action, v.Name, append(v.Items, w.Items...)}
+	return &View{Action: v.Action, Name: v.Name, Items: append(v.Items, w.Items...), concurrency: v.concurrency}
 }
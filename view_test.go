@@ -0,0 +1,97 @@
+package launchbar
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestViewConcurrentRender(t *testing.T) {
+	a := newTestAction(t, []string{"query"})
+	a.Config.data["renderTimeout"] = 0.0
+	v := a.NewView("main").SetConcurrency(4)
+
+	// every renderer waits until 4 are running at once, or gives up
+	var mu sync.Mutex
+	running, max := 0, 0
+	all := make(chan struct{})
+	var once sync.Once
+	for n := 0; n < 10; n++ {
+		v.NewItem(fmt.Sprintf("item %d", n)).
+			SetOrder(10 - n).
+			SetMatch(func(c *Context) bool { return c.Self.item.Title != "item 3" }).
+			SetRender(func(c *Context) {
+				mu.Lock()
+				running++
+				if running > max {
+					max = running
+				}
+				if running == 4 {
+					once.Do(func() { close(all) })
+				}
+				mu.Unlock()
+				select {
+				case <-all:
+				case <-time.After(time.Second):
+				}
+				mu.Lock()
+				running--
+				mu.Unlock()
+				c.Self.SetSubtitle(c.Self.item.Title + " " + c.Input.String())
+			})
+	}
+
+	items := v.Render()
+	if max != 4 {
+		t.Errorf("expected 4 renderers running at once got %d", max)
+	}
+	if len(items) != 9 {
+		t.Fatalf("expected 9 items got %d", len(items))
+	}
+	for i, item := range items {
+		if item.item.Subtitle != item.item.Title+" query" {
+			t.Errorf("item %q rendered with the wrong Self: %q", item.item.Title, item.item.Subtitle)
+		}
+		if i > 0 && items[i-1].item.Order > item.item.Order {
+			t.Errorf("items are not ordered")
+		}
+	}
+}
+
+func TestViewRenderSharesNoSelf(t *testing.T) {
+	a := newTestAction(t, nil)
+	a.Config.data["renderTimeout"] = 0.0
+	v := a.NewView("main").SetConcurrency(8)
+	for n := 0; n < 50; n++ {
+		v.NewItem(fmt.Sprint(n)).SetRender(func(c *Context) { c.Self.SetSubtitle(c.Self.item.Title) })
+	}
+	for _, item := range v.Render() {
+		if item.item.Subtitle != item.item.Title {
+			t.Errorf("item %q rendered with the wrong Self: %q", item.item.Title, item.item.Subtitle)
+		}
+	}
+}
+
+func TestViewRenderCapturedItem(t *testing.T) {
+	for _, timeout := range []float64{0, 2} {
+		a := newTestAction(t, nil)
+		a.Config.data["renderTimeout"] = timeout
+		v := a.NewView("main")
+		var item *Item
+		item = v.NewItem("captured").SetRender(func(c *Context) {
+			item.SetSubtitle("through the pointer").item.Data["a"] = 1
+			c.Self.SetIcon("self").item.Data["b"] = 2
+		})
+		item.item.Data = map[string]interface{}{}
+
+		items := v.Render()
+		if len(items) != 1 {
+			t.Fatalf("renderTimeout %v: got %d items", timeout, len(items))
+		}
+		i := items[0].item
+		if i.Subtitle != "through the pointer" || i.Icon != "self" || i.Data["a"] != 1 || i.Data["b"] != 2 {
+			t.Errorf("renderTimeout %v: the changes are lost: %+v", timeout, i)
+		}
+	}
+}
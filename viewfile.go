@@ -0,0 +1,285 @@
+package launchbar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// viewFile is the format read by LoadViews.
+type viewFile struct {
+	Views map[string]*viewSpec `json:"views"`
+}
+
+type viewSpec struct {
+	Concurrency int         `json:"concurrency,omitempty"`
+	Require     []string    `json:"require,omitempty"`
+	Items       []*itemSpec `json:"items"`
+}
+
+type itemSpec struct {
+	Title            string                 `json:"title"`
+	Subtitle         string                 `json:"subtitle,omitempty"`
+	TitleTemplate    string                 `json:"titleTemplate,omitempty"`
+	SubtitleTemplate string                 `json:"subtitleTemplate,omitempty"`
+	Icon             string                 `json:"icon,omitempty"`
+	URL              string                 `json:"url,omitempty"`
+	Path             string                 `json:"path,omitempty"`
+	QuickLookURL     string                 `json:"quickLookURL,omitempty"`
+	ActionArgument   string                 `json:"actionArgument,omitempty"`
+	Order            *int                   `json:"order,omitempty"`
+	Data             map[string]interface{} `json:"data,omitempty"`
+	Match            []string               `json:"match,omitempty"`  // named conditions, see LoadViews
+	Render           string                 `json:"render,omitempty"` // Renderer FuncMap name
+	Run              string                 `json:"run,omitempty"`    // Runner FuncMap name
+	Func             string                 `json:"func,omitempty"`   // FuncMap name called with Item.Run
+	Args             []interface{}          `json:"args,omitempty"`   // arguments of func
+	View             string                 `json:"view,omitempty"`   // view shown when the item is selected
+	Params           map[string]interface{} `json:"params,omitempty"` // params passed to view, see ShowViewWith
+}
+
+// LoadViews defines views and their static items from a json document,
+// usually embedded in the action binary. The dynamic parts are bound by name
+// to the FuncMap passed to Init, so LoadViews must be called after Init. The
+// items of a view that already exists are added to it.
+//
+//	{"views": {"main": {"items": [
+//		{"title": "Search", "icon": "SearchTemplate", "match": ["not input empty"], "run": "search"},
+//		{"title": "Settings", "match": ["modifier shift"], "view": "settings"}
+//	]}}}
+//
+// An item accepts title, subtitle, titleTemplate, subtitleTemplate (see
+// Item.SetTitleTemplate), icon, url, path, quickLookURL, actionArgument, order
+// and data. run and render name the Runner and Renderer funcs, func and args
+// call a func like Item.Run, and view shows the view with the optional params
+// (see ShowViewWith). An item sets at most one of run, func and view.
+//
+// match lists the conditions that must all be true to show the item:
+// "input empty", "input is <kind>" (see InputKind, e.g. "input is number") and
+// "modifier shift|option|command|control", each can be negated with "not ".
+//
+// An error is returned for malformed documents, unknown conditions, funcs or
+// views, or params missing a parameter the view requires; no view is defined
+// then.
+func (a *Action) LoadViews(data []byte) error {
+	var f viewFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return fmt.Errorf("views: %v", err)
+	}
+
+	names := make([]string, 0, len(f.Views))
+	for name := range f.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// validate everything before touching the action
+	for _, name := range names {
+		for n, spec := range f.Views[name].Items {
+			if err := a.checkItemSpec(f.Views, spec); err != nil {
+				return fmt.Errorf("views: view %q: item %d (%q): %v", name, n, spec.Title, err)
+			}
+		}
+	}
+
+	for _, name := range names {
+		spec := f.Views[name]
+		v := a.GetView(name)
+		if v == nil {
+			v = a.NewView(name)
+		}
+		if spec.Concurrency > 0 {
+			v.SetConcurrency(spec.Concurrency)
+		}
+		v.Require(spec.Require...)
+		for _, is := range spec.Items {
+			a.newItemFromSpec(v, is)
+		}
+	}
+	return nil
+}
+
+// LoadViewsYAML is LoadViews for a yaml document with the same structure.
+//
+//	views:
+//	  main:
+//	    items:
+//	      - title: Search
+//	        match: [not input empty]
+//	        run: search
+func (a *Action) LoadViewsYAML(data []byte) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("views: %v", err)
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("views: %v", err)
+	}
+	return a.LoadViews(b)
+}
+
+func (a *Action) lookupFunc(name string) (Func, bool) {
+	if a.funcs == nil {
+		return nil, false
+	}
+	fn, ok := (*a.funcs)[name]
+	return fn, ok
+}
+
+func (a *Action) checkItemSpec(views map[string]*viewSpec, spec *itemSpec) error {
+	for _, cond := range spec.Match {
+		if _, err := parseCondition(cond); err != nil {
+			return err
+		}
+	}
+	for _, name := range []string{spec.Render, spec.Run, spec.Func} {
+		if name == "" {
+			continue
+		}
+		if _, ok := a.lookupFunc(name); !ok {
+			return fmt.Errorf("unknown func %q", name)
+		}
+	}
+	if spec.Func == "" && len(spec.Args) > 0 {
+		return fmt.Errorf("args without func")
+	}
+	if spec.Func != "" && spec.Run != "" {
+		return fmt.Errorf("both run and func are set")
+	}
+	if spec.View == "" {
+		if spec.Params != nil {
+			return fmt.Errorf("params without view")
+		}
+		return nil
+	}
+	if spec.Run != "" {
+		return fmt.Errorf("both run and view are set")
+	}
+	if spec.Func != "" {
+		return fmt.Errorf("both func and view are set")
+	}
+	var required []string
+	if vs, ok := views[spec.View]; ok {
+		required = append(required, vs.Require...)
+	}
+	if v := a.GetView(spec.View); v != nil {
+		required = append(required, v.required...)
+	} else if _, ok := views[spec.View]; !ok {
+		return fmt.Errorf("unknown view %q", spec.View)
+	}
+	for _, key := range required {
+		if _, ok := spec.Params[key]; !ok {
+			return fmt.Errorf("view %q requires the param %q", spec.View, key)
+		}
+	}
+	return nil
+}
+
+func (a *Action) newItemFromSpec(v *View, spec *itemSpec) *Item {
+	i := v.NewItem(spec.Title).
+		SetSubtitle(spec.Subtitle).
+		SetIcon(spec.Icon).
+		SetURL(spec.URL).
+		SetPath(spec.Path).
+		SetQuickLookURL(spec.QuickLookURL).
+		SetActionArgument(spec.ActionArgument)
+	if spec.TitleTemplate != "" {
+		i.SetTitleTemplate(spec.TitleTemplate)
+	}
+	if spec.SubtitleTemplate != "" {
+		i.SetSubtitleTemplate(spec.SubtitleTemplate)
+	}
+	if spec.Order != nil {
+		i.SetOrder(*spec.Order)
+	}
+	if spec.Data != nil {
+		i.item.Data = spec.Data
+	}
+	if len(spec.Match) > 0 {
+		conds := make([]condition, len(spec.Match))
+		for n, s := range spec.Match {
+			conds[n], _ = parseCondition(s)
+		}
+		i.SetMatch(func(c *Context) bool {
+			for _, cond := range conds {
+				if !cond(c) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if spec.Render != "" {
+		fn, _ := a.lookupFunc(spec.Render)
+		i.SetRender(fn)
+	}
+	if spec.Run != "" {
+		fn, _ := a.lookupFunc(spec.Run)
+		i.SetRun(fn)
+	}
+	if spec.View != "" && spec.Params == nil {
+		i.SetRun(ShowViewFunc(spec.View))
+	} else if spec.View != "" {
+		view, params := spec.View, spec.Params
+		i.SetRun(func(c *Context) { c.Action.ShowViewWith(view, params) })
+	}
+	if spec.Func != "" {
+		i.Run(spec.Func, spec.Args...)
+	}
+	return i
+}
+
+// condition is a named match condition of LoadViews.
+type condition func(c *Context) bool
+
+var modifierConditions = map[string]func(a *Action) bool{
+	"shift":   (*Action).IsShiftKey,
+	"option":  (*Action).IsOptionKey,
+	"command": (*Action).IsCommandKey,
+	"control": (*Action).IsControlKey,
+}
+
+func parseCondition(s string) (condition, error) {
+	fields := strings.Fields(s)
+	negate := len(fields) > 0 && fields[0] == "not"
+	if negate {
+		fields = fields[1:]
+	}
+
+	var cond condition
+	switch {
+	case len(fields) == 2 && fields[0] == "input" && fields[1] == "empty":
+		cond = func(c *Context) bool { return c.Input.IsEmpty() }
+	case len(fields) == 3 && fields[0] == "input" && fields[1] == "is":
+		kind, ok := InputKind(-1), false
+		for k, name := range inputKindNames {
+			if name == fields[2] {
+				kind, ok = InputKind(k), true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("condition %q: unknown input kind %q", s, fields[2])
+		}
+		cond = func(c *Context) bool { return c.Input.Is(kind) }
+	case len(fields) == 2 && fields[0] == "modifier":
+		isDown, ok := modifierConditions[fields[1]]
+		if !ok {
+			return nil, fmt.Errorf("condition %q: unknown modifier %q", s, fields[1])
+		}
+		cond = func(c *Context) bool { return isDown(c.Action) }
+	default:
+		return nil, fmt.Errorf("unknown condition %q", s)
+	}
+
+	if negate {
+		return func(c *Context) bool { return !cond(c) }, nil
+	}
+	return cond, nil
+}
@@ -0,0 +1,152 @@
+package launchbar
+
+import (
+	"strings"
+	"testing"
+)
+
+const testViews = `{"views": {
+	"main": {"items": [
+		{"title": "Search", "match": ["not input empty"], "render": "subtitle"},
+		{"title": "Number", "match": ["input is number"], "func": "double", "args": [21]},
+		{"title": "Settings", "match": ["modifier shift"], "view": "settings", "params": {"id": 7}},
+		{"title": "Help", "order": -1, "match": ["input empty"]}
+	]},
+	"settings": {"require": ["id"], "items": [{"title": "Debug", "run": "debug"}]}
+}}`
+
+func testViewFuncs() FuncMap {
+	return FuncMap{
+		"subtitle": func(c *Context) { c.Self.SetSubtitle("for " + c.Input.String()) },
+		"double":   func(n int) int { return n * 2 },
+		"debug":    func() {},
+	}
+}
+
+func TestLoadViews(t *testing.T) {
+	tests := []struct {
+		input string
+		shift bool
+		want  []string
+	}{
+		{"", false, []string{"Help"}},
+		{"", true, []string{"Help", "Settings"}},
+		{"foo", false, []string{"Search"}},
+		{"42", false, []string{"Search", "Number"}},
+	}
+	for _, test := range tests {
+		var args []string
+		if test.input != "" {
+			args = []string{test.input}
+		}
+		shift := ""
+		if test.shift {
+			shift = "1"
+		}
+		t.Setenv("LB_OPTION_SHIFT_KEY", shift)
+		a := newTestAction(t, args, testViewFuncs())
+		if err := a.LoadViews([]byte(testViews)); err != nil {
+			t.Fatal(err)
+		}
+		if v := a.GetView("settings"); v == nil || v.checkParams(nil) == nil {
+			t.Errorf("settings view does not require id")
+		}
+		for _, i := range a.GetView("main").Items {
+			if i.item.Title != "Settings" {
+				continue
+			}
+			i.run.(func(*Context))(a.context)
+			stack := a.navStack()
+			if top := stack[len(stack)-1]; top.View != "settings" || !top.Params.Has("id") || a.GetView("settings").checkParams(top.Params) != nil {
+				t.Errorf("Settings shows %q with %v", top.View, top.Params)
+			}
+		}
+
+		var got []string
+		for _, i := range a.GetView("main").Render() {
+			got = append(got, i.item.Title)
+			if i.item.Title == "Search" && i.item.Subtitle != "for "+test.input {
+				t.Errorf("%q: Search was not rendered: %q", test.input, i.item.Subtitle)
+			}
+			if i.item.Title == "Number" && (i.item.FuncName != "double" || i.item.FuncArg != "[21]") {
+				t.Errorf("%q: Number calls %s %s", test.input, i.item.FuncName, i.item.FuncArg)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("%q shift=%v: got %v want %v", test.input, test.shift, got, test.want)
+		}
+	}
+}
+
+func TestLoadViewsErrors(t *testing.T) {
+	tests := []struct {
+		doc, err string
+	}{
+		{`{"views": {"main": {"items": [{"title": "a", "run": "nope"}]}}}`, `unknown func "nope"`},
+		{`{"views": {"main": {"items": [{"title": "a", "view": "nope"}]}}}`, `unknown view "nope"`},
+		{`{"views": {"main": {"items": [{"title": "a", "match": ["input is big"]}]}}}`, `unknown input kind "big"`},
+		{`{"views": {"main": {"items": [{"title": "a", "match": ["modifier fn"]}]}}}`, `unknown modifier "fn"`},
+		{`{"views": {"main": {"items": [{"title": "a", "match": ["sometimes"]}]}}}`, `unknown condition "sometimes"`},
+		{`{"views": {"main": {"items": [{"title": "a", "colour": "red"}]}}}`, `unknown field "colour"`},
+		{`{"views": {"main": {"items": [{"title": "a", "args": [1]}]}}}`, `args without func`},
+		{`{"views": {"main": {"items": [{"title": "a", "func": "double", "run": "debug"}]}}}`, `both run and func are set`},
+		{`{"views": {"main": {"items": [{"title": "a", "func": "double", "view": "main"}]}}}`, `both func and view are set`},
+		{`{"views": {"main": {"items": [{"title": "a", "params": {"id": 1}}]}}}`, `params without view`},
+		{`{"views": {"main": {"items": [{"title": "a", "view": "s"}]}, "s": {"require": ["id"], "items": []}}}`, `view "s" requires the param "id"`},
+		{`{"views": {"main": {"items": [{"title": "a", "view": "s", "params": {"key": 1}}]}, "s": {"require": ["id"], "items": []}}}`, `view "s" requires the param "id"`},
+	}
+	for _, test := range tests {
+		a := newTestAction(t, nil, testViewFuncs())
+		err := a.LoadViews([]byte(test.doc))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("LoadViews(%s) = %v, want %q", test.doc, err, test.err)
+		}
+		if a.GetView("main") != nil {
+			t.Errorf("LoadViews(%s) defined views on error", test.doc)
+		}
+	}
+}
+
+const testViewsYAML = `
+views:
+  main:
+    items:
+      - title: Search
+        match: [not input empty]
+        render: subtitle
+      - title: Settings
+        view: settings
+        params: {id: 7}
+  settings:
+    require: [id]
+    items:
+      - title: Debug
+        run: debug
+`
+
+func TestLoadViewsYAML(t *testing.T) {
+	a := newTestAction(t, []string{"foo"}, testViewFuncs())
+	if err := a.LoadViewsYAML([]byte(testViewsYAML)); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, i := range a.GetView("main").Render() {
+		got = append(got, i.item.Title+": "+i.item.Subtitle)
+	}
+	if want := "Search: for foo,Settings: "; strings.Join(got, ",") != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if v := a.GetView("settings"); v == nil || len(v.Items) != 1 || v.checkParams(nil) == nil {
+		t.Errorf("settings view is not loaded")
+	}
+
+	for doc, want := range map[string]string{
+		"views: [": "views: yaml",
+		"views: {main: {items: [{title: a, run: x}]}}": `unknown func "x"`,
+		"views: {main: {colour: red}}":                 `unknown field "colour"`,
+	} {
+		if err := newTestAction(t, nil, testViewFuncs()).LoadViewsYAML([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadViewsYAML(%s) = %v, want %q", doc, err, want)
+		}
+	}
+}